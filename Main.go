@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"programProject2/game"
@@ -9,11 +10,35 @@ import (
 )
 
 func main() {
+	seed := flag.Uint64("seed", 0, "layout seed (0 = random)")
+	record := flag.String("record", "", "write a replay of this run to `file` on exit")
+	replay := flag.String("replay", "", "play back the replay in `file`")
+	cont := flag.Bool("continue", false, "continue from the quick-save")
+	flag.Parse()
+
 	ebiten.SetWindowSize(800, 800)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Tile + Camera + Resolv")
 
-	g := game.NewGame()
+	cfg := game.Config{Seed: *seed, Record: *record != "", Continue: *cont}
+	if *replay != "" {
+		r, err := game.LoadReplayFile(*replay)
+		if err != nil {
+			log.Fatalf("Could not load replay %s: %v", *replay, err)
+		}
+		cfg.Seed = r.Seed
+		cfg.Input = game.NewReplayInput(r)
+	}
+
+	g := game.NewGame(cfg)
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
 	}
+
+	if *record != "" {
+		if err := g.SaveReplay(*record); err != nil {
+			log.Fatalf("Could not save replay %s: %v", *record, err)
+		}
+		log.Printf("Replay saved to %s", *record)
+	}
 }
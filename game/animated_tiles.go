@@ -0,0 +1,76 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lafriks/go-tiled"
+)
+
+// tickMillis is how much animation time one Update represents.
+func tickMillis() float64 {
+	return 1000 / float64(ebiten.TPS())
+}
+
+// tileAnimation is a tileset tile's <animation>: frames and how long
+// each one stays on screen.
+type tileAnimation struct {
+	Frames    []*ebiten.Image
+	Durations []int // ms
+	Total     int   // ms for one loop
+}
+
+// frameAt returns the frame showing t milliseconds into the animation.
+func (a *tileAnimation) frameAt(t float64) *ebiten.Image {
+	if a.Total <= 0 {
+		return a.Frames[0]
+	}
+	ms := int(t) % a.Total
+	for i, d := range a.Durations {
+		if ms < d {
+			return a.Frames[i]
+		}
+		ms -= d
+	}
+	return a.Frames[len(a.Frames)-1]
+}
+
+// animatedTile is one placed animated tile; they are drawn every frame
+// between the baked layers instead of being baked into them.
+type animatedTile struct {
+	X, Y float64 // world position
+	Anim *tileAnimation
+}
+
+// loadTileAnimations collects every animated tile from the map's
+// tilesets, keyed by global tile id.
+func loadTileAnimations(m *tiled.Map, tiles map[uint32]*ebiten.Image) map[uint32]*tileAnimation {
+	anims := make(map[uint32]*tileAnimation)
+	for _, ts := range m.Tilesets {
+		for _, t := range ts.Tiles {
+			if len(t.Animation) == 0 {
+				continue
+			}
+			a := &tileAnimation{}
+			for _, f := range t.Animation {
+				img := tiles[ts.FirstGID+f.TileID]
+				if img == nil {
+					continue
+				}
+				a.Frames = append(a.Frames, img)
+				a.Durations = append(a.Durations, int(f.Duration))
+				a.Total += int(f.Duration)
+			}
+			if len(a.Frames) > 0 {
+				anims[ts.FirstGID+t.ID] = a
+			}
+		}
+	}
+	return anims
+}
+
+// UpdateAnimations advances animated tiles and the portal by one tick.
+func (md *MapData) UpdateAnimations() {
+	md.animClock += tickMillis()
+	if md.Portal != nil && md.Portal.Anim != nil {
+		md.Portal.Anim.Update(tickMillis())
+	}
+}
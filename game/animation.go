@@ -0,0 +1,250 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Animation clip modes.
+const (
+	AnimLoop = "loop" // start over after the last frame
+	AnimOnce = "once" // stop on the last frame
+)
+
+// -------------------------------
+// Sprite sheet metadata
+// -------------------------------
+
+// sheetMeta is the JSON file stored next to a sprite sheet, e.g.
+// Assets/Sprites/player.json for player.png.
+type sheetMeta struct {
+	Image       string     `json:"image"`   // sheet file, relative to the JSON file
+	Columns     int        `json:"columns"` // grid used to cut the sheet
+	Rows        int        `json:"rows"`
+	FrameWidth  int        `json:"frame_width"` // defaults to image width / columns
+	FrameHeight int        `json:"frame_height"`
+	Scale       float64    `json:"scale"` // defaults to 1
+	Clips       []clipMeta `json:"clips"`
+}
+
+type clipMeta struct {
+	Name      string `json:"name"`
+	Row       int    `json:"row"`
+	Frames    []int  `json:"frames"`    // columns in the row, in playing order
+	Duration  int    `json:"duration"`  // ms per frame
+	Durations []int  `json:"durations"` // per-frame ms, overrides duration
+	Mode      string `json:"mode"`      // "loop" (default) or "once"
+}
+
+// Clip is one named sequence of frames.
+type Clip struct {
+	Name      string
+	Frames    []*ebiten.Image
+	Durations []int // ms
+	Mode      string
+}
+
+// AnimationSet holds every clip cut from one sprite sheet. Sets are
+// shared; each sprite plays them through its own Animation.
+type AnimationSet struct {
+	Clips map[string]*Clip
+	first string // clip an Animation starts on
+}
+
+var animationSets = map[string]*AnimationSet{}
+
+// LoadAnimationSet reads a sheet's metadata file and cuts its clips.
+// Sets are cached, so every enemy shares the same frames.
+func LoadAnimationSet(metaPath string) (*AnimationSet, error) {
+	if set, ok := animationSets[metaPath]; ok {
+		return set, nil
+	}
+
+	raw, err := EmbeddedFS.ReadFile(metaPath)
+	if err != nil {
+		return nil, err
+	}
+	var meta sheetMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("%s: %w", metaPath, err)
+	}
+
+	data, err := EmbeddedFS.ReadFile(path.Join(path.Dir(metaPath), meta.Image))
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", meta.Image, err)
+	}
+	sheet := ebiten.NewImageFromImage(img)
+
+	fw, fh := meta.FrameWidth, meta.FrameHeight
+	if fw == 0 {
+		fw = sheet.Bounds().Dx() / max(meta.Columns, 1)
+	}
+	if fh == 0 {
+		fh = sheet.Bounds().Dy() / max(meta.Rows, 1)
+	}
+	scale := meta.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	// each cell is cut (and scaled) once, even if several clips use it
+	cells := map[[2]int]*ebiten.Image{}
+	cell := func(col, row int) *ebiten.Image {
+		if c, ok := cells[[2]int{col, row}]; ok {
+			return c
+		}
+		r := image.Rect(col*fw, row*fh, (col+1)*fw, (row+1)*fh).Intersect(sheet.Bounds())
+		sub := sheet.SubImage(r).(*ebiten.Image)
+		if scale != 1 {
+			scaled := ebiten.NewImage(int(float64(r.Dx())*scale), int(float64(r.Dy())*scale))
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(scale, scale)
+			scaled.DrawImage(sub, op)
+			sub = scaled
+		}
+		cells[[2]int{col, row}] = sub
+		return sub
+	}
+
+	set := &AnimationSet{Clips: map[string]*Clip{}}
+	for _, cm := range meta.Clips {
+		if len(cm.Frames) == 0 {
+			return nil, fmt.Errorf("%s: clip %q has no frames", metaPath, cm.Name)
+		}
+		c := &Clip{Name: cm.Name, Mode: cm.Mode}
+		if c.Mode == "" {
+			c.Mode = AnimLoop
+		}
+		for i, col := range cm.Frames {
+			c.Frames = append(c.Frames, cell(col, cm.Row))
+			d := cm.Duration
+			if i < len(cm.Durations) {
+				d = cm.Durations[i]
+			}
+			c.Durations = append(c.Durations, max(d, 1))
+		}
+		set.Clips[c.Name] = c
+		if set.first == "" {
+			set.first = c.Name
+		}
+	}
+	if set.first == "" {
+		return nil, fmt.Errorf("%s: no clips", metaPath)
+	}
+
+	animationSets[metaPath] = set
+	return set, nil
+}
+
+// FrameSize is the size of the first frame of the first clip.
+func (s *AnimationSet) FrameSize() (int, int) {
+	b := s.Clips[s.first].Frames[0].Bounds()
+	return b.Dx(), b.Dy()
+}
+
+// -------------------------------
+// Playback
+// -------------------------------
+
+// Animation plays the clips of a set in milliseconds, so the speed does
+// not depend on the tick rate.
+type Animation struct {
+	Set *AnimationSet
+
+	// OnFinish is called when a "once" clip reaches its end, and each
+	// time a looping clip wraps around.
+	OnFinish func(clip string)
+
+	clip    *Clip
+	frame   int
+	elapsed float64 // ms spent on the current frame
+	done    bool
+}
+
+func NewAnimation(set *AnimationSet) *Animation {
+	a := &Animation{Set: set}
+	a.Play(set.first)
+	return a
+}
+
+// Play switches to the named clip from its first frame. Playing the
+// clip that is already running does nothing; unknown names are ignored.
+func (a *Animation) Play(name string) {
+	if a.clip != nil && a.clip.Name == name {
+		return
+	}
+	c := a.Set.Clips[name]
+	if c == nil {
+		return
+	}
+	a.clip = c
+	a.Restart()
+}
+
+// Restart plays the current clip again from the start.
+func (a *Animation) Restart() {
+	a.frame = 0
+	a.elapsed = 0
+	a.done = false
+}
+
+// SetFrame jumps to a frame of the current clip (wrapping around).
+func (a *Animation) SetFrame(i int) {
+	n := len(a.clip.Frames)
+	a.frame = ((i % n) + n) % n
+	a.elapsed = 0
+}
+
+// Update advances the animation by ms milliseconds.
+func (a *Animation) Update(ms float64) {
+	if a.done {
+		return
+	}
+	a.elapsed += ms
+	for a.elapsed >= float64(a.clip.Durations[a.frame]) {
+		a.elapsed -= float64(a.clip.Durations[a.frame])
+		if a.frame < len(a.clip.Frames)-1 {
+			a.frame++
+			continue
+		}
+
+		name := a.clip.Name
+		if a.clip.Mode == AnimOnce {
+			a.done = true
+			a.elapsed = 0
+		} else {
+			a.frame = 0
+		}
+		if a.OnFinish != nil {
+			a.OnFinish(name)
+		}
+		// the callback may have switched clips; carry on with that one
+		if a.done || a.clip.Name != name {
+			return
+		}
+	}
+}
+
+// Image is the frame to draw right now.
+func (a *Animation) Image() *ebiten.Image {
+	return a.clip.Frames[a.frame]
+}
+
+// Clip is the name of the playing clip.
+func (a *Animation) Clip() string {
+	return a.clip.Name
+}
+
+// Done reports whether a "once" clip has finished.
+func (a *Animation) Done() bool {
+	return a.done
+}
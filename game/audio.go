@@ -0,0 +1,206 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+const (
+	audioSampleRate = 44100
+	musicFadeTicks  = 90  // length of a music crossfade
+	volumeStep      = 0.1 // one Options menu step
+)
+
+// Sound effects, by embedded path. Both .wav and .ogg files load.
+const (
+	SfxFish        = "Assets/Audio/fish.wav"
+	SfxBadCan      = "Assets/Audio/bad_can.wav"
+	SfxPortalOpen  = "Assets/Audio/portal_open.wav"
+	SfxPortalEnter = "Assets/Audio/portal_enter.wav"
+	SfxGameOver    = "Assets/Audio/game_over.wav"
+)
+
+var allSfx = []string{SfxFish, SfxBadCan, SfxPortalOpen, SfxPortalEnter, SfxGameOver}
+
+// -------------------------------
+// Audio manager
+// -------------------------------
+
+// Audio plays the level music and one-shot sound effects. A silent
+// Audio (headless games) keeps its volume settings but plays nothing.
+type Audio struct {
+	Master, Music, SFX float64 // 0..1
+	Muted              bool
+
+	ctx    *audio.Context    // nil when silent
+	sounds map[string][]byte // decoded effects, by path
+	music  *musicTrack       // playing or fading in
+	old    *musicTrack       // fading out
+}
+
+type musicTrack struct {
+	name   string
+	player *audio.Player
+	fade   float64 // 0..1, scales the music volume during a crossfade
+}
+
+func NewAudio(silent bool) *Audio {
+	a := &Audio{Master: 0.8, Music: 0.6, SFX: 0.8, sounds: map[string][]byte{}}
+	if silent {
+		return a
+	}
+
+	// ebiten allows one context per process
+	a.ctx = audio.CurrentContext()
+	if a.ctx == nil {
+		a.ctx = audio.NewContext(audioSampleRate)
+	}
+
+	// decode the effects up front so the first pickup doesn't stall
+	for _, name := range allSfx {
+		if _, err := a.sound(name); err != nil {
+			log.Printf("⚠️ Could not load sound %s: %v", name, err)
+		}
+	}
+	return a
+}
+
+// decode opens an embedded .wav or .ogg file as a stream at the
+// context's sample rate.
+func (a *Audio) decode(name string) (io.ReadSeeker, int64, error) {
+	data, err := EmbeddedFS.ReadFile(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	sr := a.ctx.SampleRate()
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wav":
+		s, err := wav.DecodeWithSampleRate(sr, bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, err
+		}
+		return s, s.Length(), nil
+	case ".ogg":
+		s, err := vorbis.DecodeWithSampleRate(sr, bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, err
+		}
+		return s, s.Length(), nil
+	}
+	return nil, 0, fmt.Errorf("unsupported audio format %q", path.Ext(name))
+}
+
+// sound returns a decoded effect, decoding it on first use.
+func (a *Audio) sound(name string) ([]byte, error) {
+	if pcm, ok := a.sounds[name]; ok {
+		return pcm, nil
+	}
+	s, _, err := a.decode(name)
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := io.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+	a.sounds[name] = pcm
+	return pcm, nil
+}
+
+// PlaySFX plays an effect once. Effects can overlap.
+func (a *Audio) PlaySFX(name string) {
+	if a.ctx == nil {
+		return
+	}
+	pcm, err := a.sound(name)
+	if err != nil {
+		log.Printf("⚠️ Could not play %s: %v", name, err)
+		return
+	}
+	p := a.ctx.NewPlayerFromBytes(pcm)
+	p.SetVolume(a.sfxVolume())
+	p.Play()
+}
+
+// PlayMusic crossfades to a looping track; "" fades the music out.
+// Asking for the track that is already playing does nothing.
+func (a *Audio) PlayMusic(name string) {
+	if a.ctx == nil {
+		return
+	}
+	if a.music != nil && a.music.name == name {
+		return
+	}
+
+	// a third track cuts off whatever was still fading out
+	if a.old != nil {
+		a.old.player.Close()
+	}
+	a.old, a.music = a.music, nil
+	if name == "" {
+		return
+	}
+
+	s, length, err := a.decode(name)
+	if err != nil {
+		log.Printf("⚠️ Could not play music %s: %v", name, err)
+		return
+	}
+	p, err := a.ctx.NewPlayer(audio.NewInfiniteLoop(s, length))
+	if err != nil {
+		log.Printf("⚠️ Could not play music %s: %v", name, err)
+		return
+	}
+	a.music = &musicTrack{name: name, player: p}
+	p.SetVolume(0)
+	p.Play()
+}
+
+// Update runs the crossfade and applies volume changes. Call once per tick.
+func (a *Audio) Update() {
+	if a.ctx == nil {
+		return
+	}
+	step := 1.0 / musicFadeTicks
+	if a.music != nil {
+		a.music.fade = min(a.music.fade+step, 1)
+		a.music.player.SetVolume(a.musicVolume() * a.music.fade)
+	}
+	if a.old != nil {
+		a.old.fade = max(a.old.fade-step, 0)
+		a.old.player.SetVolume(a.musicVolume() * a.old.fade)
+		if a.old.fade == 0 {
+			a.old.player.Close()
+			a.old = nil
+		}
+	}
+}
+
+func (a *Audio) musicVolume() float64 {
+	if a.Muted {
+		return 0
+	}
+	return a.Master * a.Music
+}
+
+func (a *Audio) sfxVolume() float64 {
+	if a.Muted {
+		return 0
+	}
+	return a.Master * a.SFX
+}
+
+// stepVolume moves a volume by whole menu steps, kept within 0..1.
+func stepVolume(v float64, steps int) float64 {
+	v = min(max(v+float64(steps)*volumeStep, 0), 1)
+	return math.Round(v/volumeStep) * volumeStep
+}
@@ -1,114 +1,267 @@
 package game
 
 import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-// Camera controls what region of the world is rendered.
+// Camera defaults, in world pixels.
+const (
+	cameraDeadZoneW = 64
+	cameraDeadZoneH = 48
+	cameraFollow    = 0.12 // share of the remaining distance covered per tick
+	cameraView      = 400  // world pixels across the shorter screen side at zoom 1
+)
+
+// zoomLevels are the steps the zoom keys move through.
+var zoomLevels = []float64{0.5, 0.75, 1, 1.5, 2, 3}
+
+// Camera controls what region of the world is rendered. It keeps its
+// own position, moved once per tick by Update, so everything drawn in
+// world space agrees on where the view is.
 type Camera struct {
-	W, H int
+	W, H int     // world pixels in view, set by Resize
+	X, Y float64 // world position of the view's top-left
+
+	// Reference is how many world pixels span the shorter side of the
+	// screen at Zoom 1; 0 means one world pixel per screen pixel. With
+	// PixelPerfect the scale is rounded down to a whole number and the
+	// view is letterboxed in the middle of the screen.
+	Reference    float64
+	Zoom         float64
+	PixelPerfect bool
+
+	// The target can move inside the dead zone (centered on the view)
+	// without the camera following; outside it the camera eases toward
+	// the target by Follow each tick (1 snaps).
+	DeadZoneW, DeadZoneH float64
+	Follow               float64
+
+	Lighting *Lighting // nil on fully lit levels
+
+	// Rand drives the shake. NewCamera uses a fixed stream; Game swaps in
+	// one derived from its seed, so replays shake the same way.
+	Rand *rand.Rand
+
+	shakeIntensity float64
+	shakeTicks     int
+	shakeTotal     int
+	shakeX, shakeY float64
+
+	scale      float64       // screen pixels per world pixel, same on both axes
+	offX, offY float64       // letterbox margins, in screen pixels
+	view       *ebiten.Image // reused every frame, recreated only when W/H change
 }
 
-func NewCamera(w, h int) *Camera {
-	return &Camera{W: w, H: h}
+// NewCamera makes a camera for a screenW x screenH screen; see
+// Camera.Reference.
+func NewCamera(reference float64, screenW, screenH int) *Camera {
+	c := &Camera{
+		Reference: reference,
+		Zoom:      1,
+		DeadZoneW: cameraDeadZoneW,
+		DeadZoneH: cameraDeadZoneH,
+		Follow:    cameraFollow,
+		Rand:      rand.New(rand.NewPCG(0, 0)),
+	}
+	c.Resize(screenW, screenH)
+	return c
 }
 
-// Draw draws the map, items, player, enemies, and optionally the heart in camera/world space.
-func (c *Camera) Draw(screen *ebiten.Image, md *MapData, player *Player, heart *Heart) {
-	// Center camera on the player
-	camX := player.X - float64(c.W)/2
-	camY := player.Y - float64(c.H)/2
+// Resize works out the scale and the size of the view for the screen.
+// The scale is the same on both axes, so a wide window shows more of
+// the world instead of stretching it.
+func (c *Camera) Resize(screenW, screenH int) {
+	scale := c.Zoom
+	if c.Reference > 0 {
+		scale *= float64(min(screenW, screenH)) / c.Reference
+	}
+	if c.PixelPerfect {
+		scale = math.Max(1, math.Floor(scale))
+	}
+	c.scale = scale
 
-	// Clamp left & top
-	if camX < 0 {
-		camX = 0
+	if c.PixelPerfect {
+		c.W = int(float64(screenW) / scale)
+		c.H = int(float64(screenH) / scale)
+		c.offX = math.Floor((float64(screenW) - float64(c.W)*scale) / 2)
+		c.offY = math.Floor((float64(screenH) - float64(c.H)*scale) / 2)
+	} else {
+		c.W = int(math.Ceil(float64(screenW) / scale))
+		c.H = int(math.Ceil(float64(screenH) / scale))
+		c.offX, c.offY = 0, 0
 	}
-	if camY < 0 {
-		camY = 0
+}
+
+// ZoomStep moves to the next zoom level in (dir > 0) or out, keeping
+// the same point in the middle of the view.
+func (c *Camera) ZoomStep(dir, screenW, screenH int) {
+	i := 0
+	for i < len(zoomLevels)-1 && zoomLevels[i] < c.Zoom {
+		i++
 	}
+	i = max(0, min(len(zoomLevels)-1, i+dir))
 
-	// Clamp right & bottom
-	if md != nil {
-		maxCamX := float64(md.Width) - float64(c.W)
-		if camX > maxCamX {
-			camX = maxCamX
-		}
+	cx := c.X + float64(c.W)/2
+	cy := c.Y + float64(c.H)/2
+	c.Zoom = zoomLevels[i]
+	c.Resize(screenW, screenH)
+	c.X = cx - float64(c.W)/2
+	c.Y = cy - float64(c.H)/2
+}
 
-		maxCamY := float64(md.Height) - float64(c.H)
-		if camY > maxCamY {
-			camY = maxCamY
-		}
+// -------------------------------
+// Movement
+// -------------------------------
+
+// Update follows the world point (tx, ty) and keeps the view inside a
+// boundsW x boundsH world.
+func (c *Camera) Update(tx, ty float64, boundsW, boundsH int) {
+	cx := c.X + float64(c.W)/2
+	cy := c.Y + float64(c.H)/2
+	goalX, goalY := c.X, c.Y
+	if dx := tx - cx; math.Abs(dx) > c.DeadZoneW/2 {
+		goalX += dx - math.Copysign(c.DeadZoneW/2, dx)
 	}
+	if dy := ty - cy; math.Abs(dy) > c.DeadZoneH/2 {
+		goalY += dy - math.Copysign(c.DeadZoneH/2, dy)
+	}
+	c.X += (goalX - c.X) * c.Follow
+	c.Y += (goalY - c.Y) * c.Follow
+	c.clamp(boundsW, boundsH)
 
-	// Camera view buffer
-	cameraView := ebiten.NewImage(c.W, c.H)
+	c.shakeX, c.shakeY = 0, 0
+	if c.shakeTicks > 0 {
+		// fades out over the shake's duration
+		amp := c.shakeIntensity * float64(c.shakeTicks) / float64(c.shakeTotal)
+		c.shakeX = (c.Rand.Float64()*2 - 1) * amp
+		c.shakeY = (c.Rand.Float64()*2 - 1) * amp
+		c.shakeTicks--
+	}
+}
 
-	// Draw tilemap + world objects (items, portal, enemies)
-	if md != nil {
-		// Draw tilemap (world)
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(-camX, -camY)
-		cameraView.DrawImage(md.Image, op)
+// CenterOn jumps straight to (tx, ty), e.g. when a level starts.
+func (c *Camera) CenterOn(tx, ty float64, boundsW, boundsH int) {
+	c.X = tx - float64(c.W)/2
+	c.Y = ty - float64(c.H)/2
+	c.clamp(boundsW, boundsH)
+}
+
+func (c *Camera) clamp(boundsW, boundsH int) {
+	c.X = math.Max(0, math.Min(c.X, float64(boundsW-c.W)))
+	c.Y = math.Max(0, math.Min(c.Y, float64(boundsH-c.H)))
+}
+
+// Shake jolts the view by up to intensity world pixels, fading out over
+// duration ticks. A stronger shake replaces a weaker one.
+func (c *Camera) Shake(intensity float64, duration int) {
+	if c.shakeTicks > 0 && c.shakeIntensity > intensity {
+		return
+	}
+	c.shakeIntensity = intensity
+	c.shakeTicks = duration
+	c.shakeTotal = max(duration, 1)
+}
 
-		// Draw good items
+// viewX/viewY are the top-left of what is actually drawn, shake included.
+func (c *Camera) viewX() float64 { return c.X + c.shakeX }
+func (c *Camera) viewY() float64 { return c.Y + c.shakeY }
+
+// WorldToScreen converts a world position to screen pixels.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return (x-c.viewX())*c.scale + c.offX, (y-c.viewY())*c.scale + c.offY
+}
+
+// ScreenToWorld converts screen pixels to a world position.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return (x-c.offX)/c.scale + c.viewX(), (y-c.offY)/c.scale + c.viewY()
+}
+
+// worldSprite is one actor image placed in world space.
+type worldSprite struct {
+	Img  *ebiten.Image
+	X, Y float64
+}
+
+// baseY is the bottom edge of the sprite, used for depth sorting.
+func (sp worldSprite) baseY() float64 {
+	return sp.Y + float64(sp.Img.Bounds().Dy())
+}
+
+// collectSprites gathers items, portal, enemies, the player and the heart
+// with the frame each one's animation is on.
+func collectSprites(md *MapData, player *Player, heart *Heart) []worldSprite {
+	var out []worldSprite
+
+	if md != nil {
 		for _, it := range md.Items {
-			op2 := &ebiten.DrawImageOptions{}
-			op2.GeoM.Translate(it.X-camX, it.Y-camY)
-			cameraView.DrawImage(it.Img, op2)
+			out = append(out, worldSprite{Img: it.Img, X: it.X, Y: it.Y})
 		}
-
-		// Draw bad items
 		for _, it := range md.BadItems {
-			op3 := &ebiten.DrawImageOptions{}
-			op3.GeoM.Translate(it.X-camX, it.Y-camY)
-			cameraView.DrawImage(it.Img, op3)
+			out = append(out, worldSprite{Img: it.Img, X: it.X, Y: it.Y})
 		}
-
-		// Draw portal
 		if md.Portal != nil && md.Portal.Active {
-			op4 := &ebiten.DrawImageOptions{}
-			op4.GeoM.Translate(md.Portal.X-camX, md.Portal.Y-camY)
-			cameraView.DrawImage(md.Portal.Img, op4)
+			out = append(out, worldSprite{Img: md.Portal.Anim.Image(), X: md.Portal.X, Y: md.Portal.Y})
 		}
-
-		// Draw enemies (if any)
 		for _, e := range md.Enemies {
-			if len(e.Images) == 0 {
-				continue
-			}
-			frame := (e.Frame / 6) % len(e.Images)
-			eImg := e.Images[frame]
-			opE := &ebiten.DrawImageOptions{}
-			opE.GeoM.Translate(e.X-camX, e.Y-camY)
-			cameraView.DrawImage(eImg, opE)
+			out = append(out, worldSprite{Img: e.Anim.Image(), X: e.X, Y: e.Y})
 		}
 	}
 
-	// Draw player
-	if player != nil && len(player.Anim) > 0 {
-		frames := player.Anim[player.Dir]
-		if len(frames) > 0 {
-			frame := (player.Frame / 6) % len(frames)
-			img := frames[frame]
-
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(player.X-camX, player.Y-camY)
-			cameraView.DrawImage(img, op)
-		}
+	if player != nil && player.Visible() {
+		out = append(out, worldSprite{Img: player.Anim.Image(), X: player.X, Y: player.Y})
 	}
 
-	// Draw heart (world space) – used on Game Over screen
+	// used on Game Over screen
 	if heart != nil {
+		out = append(out, worldSprite{Img: heart.Img, X: heart.X, Y: heart.Y})
+	}
+	return out
+}
+
+// Draw draws the map, items, player, enemies, and optionally the heart
+// as seen from the camera's current position.
+func (c *Camera) Draw(screen *ebiten.Image, md *MapData, player *Player, heart *Heart) {
+	camX, camY := c.viewX(), c.viewY()
+
+	// Camera view buffer
+	if c.view == nil || c.view.Bounds().Dx() != c.W || c.view.Bounds().Dy() != c.H {
+		c.view = ebiten.NewImage(c.W, c.H)
+	}
+	cameraView := c.view
+	cameraView.Clear()
+
+	// Draw tilemap (layers under actors)
+	if md != nil {
+		md.drawChunks(cameraView, camX, camY, false)
+	}
+
+	// Draw actors back to front so lower sprites overlap higher ones
+	sprites := collectSprites(md, player, heart)
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].baseY() < sprites[j].baseY()
+	})
+	for _, sp := range sprites {
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(heart.X-camX, heart.Y-camY)
-		cameraView.DrawImage(heart.Img, op)
+		op.GeoM.Translate(sp.X-camX, sp.Y-camY)
+		cameraView.DrawImage(sp.Img, op)
 	}
 
-	// Scale camera view to final window
-	scaleX := float64(screen.Bounds().Dx()) / float64(c.W)
-	scaleY := float64(screen.Bounds().Dy()) / float64(c.H)
+	// Draw layers flagged above_actors (roofs, canopies, arches)
+	if md != nil {
+		md.drawChunks(cameraView, camX, camY, true)
+	}
+
+	if md != nil && c.Lighting != nil {
+		c.Lighting.Apply(cameraView, md, player, camX, camY)
+	}
 
+	// Scale camera view to final window
 	finalOp := &ebiten.DrawImageOptions{}
-	finalOp.GeoM.Scale(scaleX, scaleY)
+	finalOp.GeoM.Scale(c.scale, c.scale)
+	finalOp.GeoM.Translate(c.offX, c.offY)
 	screen.DrawImage(cameraView, finalOp)
 }
@@ -0,0 +1,176 @@
+package game
+
+import (
+	"image"
+
+	"github.com/solarlune/resolv"
+)
+
+// Tags for the shapes in MapData.Space.
+var (
+	TagSolid   = resolv.NewTag("solid")
+	TagItem    = resolv.NewTag("item")
+	TagBadItem = resolv.NewTag("bad_item")
+	TagPortal  = resolv.NewTag("portal")
+)
+
+// -------------------------------
+// Collision world
+// -------------------------------
+
+// initSpace creates the level's collision space, one cell per tile, so
+// a query only looks at the shapes in the cells it overlaps instead of
+// every shape on the map.
+func (md *MapData) initSpace() {
+	md.Space = resolv.NewSpace(md.Width, md.Height, md.TileW, md.TileH)
+}
+
+func (md *MapData) addShape(s resolv.IShape, tags resolv.Tags) {
+	s.Tags().Set(tags)
+	md.Space.Add(s)
+}
+
+func (md *MapData) removeShape(s resolv.IShape) {
+	if s != nil {
+		md.Space.Remove(s)
+	}
+}
+
+// Touching returns the shapes carrying any of tags that intersect s.
+// s does not need to be in the space itself.
+func (md *MapData) Touching(s resolv.IShape, tags resolv.Tags) []resolv.IShape {
+	var hits []resolv.IShape
+	md.Space.FilterCells(s.Bounds()).FilterShapes().ByTags(tags).ForEach(func(other resolv.IShape) bool {
+		if other != s && s.IsIntersecting(other) {
+			hits = append(hits, other)
+		}
+		return true
+	})
+	return hits
+}
+
+func containsShape(shapes []resolv.IShape, s resolv.IShape) bool {
+	for _, o := range shapes {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CollidesSolid reports whether s overlaps a wall.
+func (md *MapData) CollidesSolid(s resolv.IShape) bool {
+	hit := false
+	md.Space.FilterCells(s.Bounds()).FilterShapes().ByTags(TagSolid).ForEach(func(other resolv.IShape) bool {
+		if !hit && other != s && s.IsIntersecting(other) {
+			hit = true
+		}
+		return !hit
+	})
+	return hit
+}
+
+// SolidMTV returns how far s has to move to get out of the wall it
+// overlaps most, and false when it overlaps none.
+func (md *MapData) SolidMTV(s resolv.IShape) (resolv.Vector, bool) {
+	var best resolv.Vector
+	found := false
+	md.Space.FilterCells(s.Bounds()).FilterShapes().ByTags(TagSolid).ForEach(func(other resolv.IShape) bool {
+		if other == s {
+			return true
+		}
+		set := s.Intersection(other)
+		if set.IsEmpty() {
+			return true
+		}
+		if !found || set.MTV.Magnitude() > best.Magnitude() {
+			best = set.MTV
+			found = true
+		}
+		return true
+	})
+	return best, found
+}
+
+// -------------------------------
+// Pickups and the portal
+// -------------------------------
+func (md *MapData) addItem(x, y float64) {
+	box := makeItemRect(x, y, md.itemImg)
+	md.addShape(box, TagItem)
+	md.Items = append(md.Items, PlacedItem{X: x, Y: y, Img: md.itemImg, Box: box})
+}
+
+func (md *MapData) addBadItem(x, y float64) {
+	box := makeBadItemRect(x, y, md.badItemImg)
+	md.addShape(box, TagBadItem)
+	md.BadItems = append(md.BadItems, PlacedItem{X: x, Y: y, Img: md.badItemImg, Box: box})
+}
+
+// clearItems takes every good and bad item off the map.
+func (md *MapData) clearItems() {
+	for _, it := range md.Items {
+		md.removeShape(it.Box)
+	}
+	for _, it := range md.BadItems {
+		md.removeShape(it.Box)
+	}
+	md.Items = nil
+	md.BadItems = nil
+}
+
+// setPortal swaps the current portal (if any) for p, which may be nil.
+func (md *MapData) setPortal(p *Portal) {
+	if md.Portal != nil {
+		md.removeShape(md.Portal.Box)
+	}
+	md.Portal = p
+	if p != nil {
+		md.addShape(p.Box, TagPortal)
+	}
+}
+
+// mergeSolidCells greedily covers the solid cells of a w x h grid with
+// non-overlapping rectangles (in tiles): each unused solid cell starts a
+// run that grows right as far as it can, then down while the whole run
+// below is solid too.
+func mergeSolidCells(grid []bool, w, h int) []image.Rectangle {
+	used := make([]bool, len(grid))
+	free := func(x, y int) bool {
+		i := y*w + x
+		return grid[i] && !used[i]
+	}
+
+	var rects []image.Rectangle
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !free(x, y) {
+				continue
+			}
+
+			x1 := x + 1
+			for x1 < w && free(x1, y) {
+				x1++
+			}
+
+			y1 := y + 1
+		grow:
+			for y1 < h {
+				for cx := x; cx < x1; cx++ {
+					if !free(cx, y1) {
+						break grow
+					}
+				}
+				y1++
+			}
+
+			for cy := y; cy < y1; cy++ {
+				for cx := x; cx < x1; cx++ {
+					used[cy*w+cx] = true
+				}
+			}
+			rects = append(rects, image.Rect(x, y, x1, y1))
+		}
+	}
+	return rects
+}
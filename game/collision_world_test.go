@@ -0,0 +1,210 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/solarlune/resolv"
+)
+
+// These compare the resolv space against what it replaced: one box per
+// solid tile (and per item) in a slice, checked by a linear scan.
+
+const benchMapSize = 200 // tiles per side
+
+// perTileSolids builds the old collision list, one box per solid tile.
+func perTileSolids(md *MapData) []resolv.IShape {
+	var out []resolv.IShape
+	for i, solid := range md.solidGrid {
+		if solid {
+			x, y := i%md.Map.Width, i/md.Map.Width
+			out = append(out, resolv.NewRectangle(
+				float64(x*md.TileW), float64(y*md.TileH), float64(md.TileW), float64(md.TileH),
+			))
+		}
+	}
+	return out
+}
+
+// probeBoxes scatters player-sized boxes over the map, walls included.
+func probeBoxes(md *MapData, n int) []resolv.IShape {
+	rng := rand.New(rand.NewPCG(1, 2))
+	boxes := make([]resolv.IShape, n)
+	for i := range boxes {
+		boxes[i] = resolv.NewRectangle(rng.Float64()*float64(md.Width), rng.Float64()*float64(md.Height), 16, 27)
+	}
+	return boxes
+}
+
+func BenchmarkLoadMapFile(b *testing.B) {
+	b.Run("floor1", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			LoadMapFile(largeMapPath)
+		}
+	})
+	b.Run("200x200", func(b *testing.B) {
+		m, tiles := largeTiledMap(b, benchMapSize, benchMapSize)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			newMapData(m, tiles)
+		}
+	})
+}
+
+// BenchmarkFreeTiles finds every walkable tile, the load-time step that
+// used to test each tile against every wall box.
+func BenchmarkFreeTiles(b *testing.B) {
+	md := newMapData(largeTiledMap(b, benchMapSize, benchMapSize))
+	solids := perTileSolids(md)
+	w, h := md.Map.Width, md.Map.Height
+	tileRect := func(x, y int) resolv.IShape {
+		return resolv.NewRectangle(float64(x*md.TileW), float64(y*md.TileH), float64(md.TileW), float64(md.TileH))
+	}
+
+	b.Run("rect_scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var free [][2]int
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					r := tileRect(x, y)
+					solid := false
+					for _, s := range solids {
+						if r.IsIntersecting(s) {
+							solid = true
+							break
+						}
+					}
+					if !solid {
+						free = append(free, [2]int{x, y})
+					}
+				}
+			}
+		}
+	})
+	b.Run("space", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var free [][2]int
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					if !md.CollidesSolid(tileRect(x, y)) {
+						free = append(free, [2]int{x, y})
+					}
+				}
+			}
+		}
+	})
+	b.Run("solid_grid", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var free [][2]int
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					if !md.IsSolidTile(x, y) {
+						free = append(free, [2]int{x, y})
+					}
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkCollidesSolid(b *testing.B) {
+	md := newMapData(largeTiledMap(b, benchMapSize, benchMapSize))
+	solids := perTileSolids(md)
+	boxes := probeBoxes(md, 256)
+
+	b.Run("space", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			md.CollidesSolid(boxes[i%len(boxes)])
+		}
+	})
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			box := boxes[i%len(boxes)]
+			for _, s := range solids {
+				if box.IsIntersecting(s) {
+					break
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkTouchingItems(b *testing.B) {
+	md := newMapData(largeTiledMap(b, benchMapSize, benchMapSize))
+	if !md.loadItemImages() {
+		b.Fatal("could not load item images")
+	}
+	// a fish on every third free tile
+	for y := 0; y < md.Map.Height; y += 3 {
+		for x := 0; x < md.Map.Width; x += 3 {
+			if !md.IsSolidTile(x, y) {
+				md.addItem(float64(x*md.TileW), float64(y*md.TileH))
+			}
+		}
+	}
+	boxes := probeBoxes(md, 256)
+
+	b.Run("space", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			md.Touching(boxes[i%len(boxes)], TagItem)
+		}
+	})
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			box := boxes[i%len(boxes)]
+			var hits []resolv.IShape
+			for _, it := range md.Items {
+				if box.IsIntersecting(it.Box) {
+					hits = append(hits, it.Box)
+				}
+			}
+		}
+	})
+}
+
+func TestMergeSolidCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		rects int
+	}{
+		{"empty", []string{"...", "..."}, 0},
+		{"full", []string{"###", "###"}, 1},
+		{"two walls", []string{"#.#", "#.#", "#.#"}, 2},
+		{"L shape", []string{"##.", "#..", "#.."}, 2},
+		{"U shape", []string{"#.#", "###"}, 3},
+		{"checkerboard", []string{"#.#", ".#."}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := len(tt.rows[0]), len(tt.rows)
+			grid := make([]bool, w*h)
+			for y, row := range tt.rows {
+				for x, c := range row {
+					grid[y*w+x] = c == '#'
+				}
+			}
+
+			rects := mergeSolidCells(grid, w, h)
+			if len(rects) != tt.rects {
+				t.Errorf("got %d rects, want %d: %v", len(rects), tt.rects, rects)
+			}
+			covered := make([]int, w*h)
+			for _, r := range rects {
+				for y := r.Min.Y; y < r.Max.Y; y++ {
+					for x := r.Min.X; x < r.Max.X; x++ {
+						covered[y*w+x]++
+					}
+				}
+			}
+			for i, n := range covered {
+				switch {
+				case grid[i] && n != 1:
+					t.Errorf("solid cell (%d,%d) covered %d times", i%w, i/w, n)
+				case !grid[i] && n != 0:
+					t.Errorf("open cell (%d,%d) covered", i%w, i/w)
+				}
+			}
+		})
+	}
+}
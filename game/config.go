@@ -0,0 +1,10 @@
+package game
+
+// Config holds the start-up options passed in from the command line.
+type Config struct {
+	Seed     uint64      // layout seed; 0 = use the manifest seed, or pick one at random
+	Input    InputSource // nil = keyboard
+	Record   bool        // keep a replay of every tick (see Game.SaveReplay)
+	Continue bool        // start from the quick-save if there is one
+	Silent   bool        // no audio (headless runs)
+}
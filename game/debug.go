@@ -0,0 +1,139 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+var (
+	solidDebugColor   = color.RGBA{255, 60, 60, 255}
+	gridDebugColor    = color.RGBA{255, 255, 255, 40}
+	playerDebugColor  = color.RGBA{60, 255, 60, 255}
+	itemDebugColor    = color.RGBA{60, 200, 255, 255}
+	badItemDebugColor = color.RGBA{255, 160, 40, 255}
+	portalDebugColor  = color.RGBA{200, 80, 255, 255}
+	enemyDebugColor   = color.RGBA{255, 60, 200, 255}
+	heartDebugColor   = color.RGBA{255, 100, 100, 255}
+	viewDebugColor    = color.RGBA{255, 255, 0, 255}
+	zoneDebugColor    = color.RGBA{255, 255, 0, 120}
+)
+
+// -------------------------------
+// Debug views
+// -------------------------------
+func (g *Game) updateDebugKeys() {
+	if g.justPressed(ebiten.KeyF3) {
+		g.showDebug = !g.showDebug
+	}
+	if g.justPressed(ebiten.KeyF4) {
+		g.showSolids = !g.showSolids
+	}
+}
+
+// drawDebug draws the F4 collision boxes and the F3 overlay on top of
+// the last Camera.Draw. md is nil on the game-over screen.
+func (g *Game) drawDebug(screen *ebiten.Image, md *MapData, player *Player) {
+	if md != nil && (g.showSolids || g.showDebug) {
+		g.Camera.strokeShapes(screen, md.SolidTiles, solidDebugColor)
+	}
+	if !g.showDebug {
+		return
+	}
+
+	if md != nil {
+		g.Camera.drawGrid(screen, md)
+		var items, bad, enemies []resolv.IShape
+		for _, it := range md.Items {
+			items = append(items, it.Box)
+		}
+		for _, it := range md.BadItems {
+			bad = append(bad, it.Box)
+		}
+		for _, e := range md.Enemies {
+			enemies = append(enemies, e.Box)
+		}
+		g.Camera.strokeShapes(screen, items, itemDebugColor)
+		g.Camera.strokeShapes(screen, bad, badItemDebugColor)
+		g.Camera.strokeShapes(screen, enemies, enemyDebugColor)
+		if md.Portal != nil {
+			g.Camera.strokeShapes(screen, []resolv.IShape{md.Portal.Box}, portalDebugColor)
+		}
+	}
+	if g.Heart != nil {
+		heartRect := makeHeartRect(g.Heart.X, g.Heart.Y, g.Heart.Img)
+		g.Camera.strokeShapes(screen, []resolv.IShape{heartRect}, heartDebugColor)
+	}
+	if player != nil {
+		g.Camera.strokeShapes(screen, []resolv.IShape{player.Box}, playerDebugColor)
+	}
+
+	// camera: the world area in view and the dead zone in its middle
+	c := g.Camera
+	view := resolv.NewRectangleFromTopLeft(c.X, c.Y, float64(c.W), float64(c.H))
+	deadZone := resolv.NewRectangleFromTopLeft(
+		c.X+(float64(c.W)-c.DeadZoneW)/2, c.Y+(float64(c.H)-c.DeadZoneH)/2, c.DeadZoneW, c.DeadZoneH,
+	)
+	c.strokeShapes(screen, []resolv.IShape{view}, viewDebugColor)
+	c.strokeShapes(screen, []resolv.IShape{deadZone}, zoneDebugColor)
+
+	g.drawDebugText(screen, md, player)
+}
+
+func (g *Game) drawDebugText(screen *ebiten.Image, md *MapData, player *Player) {
+	msg := fmt.Sprintf("FPS %.1f  TPS %.1f  tick %d\ncamera %.0f,%.0f",
+		ebiten.ActualFPS(), ebiten.ActualTPS(), g.Tick, g.Camera.X, g.Camera.Y)
+	if md != nil && player != nil {
+		t := player.Tile(md)
+		msg += fmt.Sprintf("\nplayer %.0f,%.0f  tile %d,%d  hp %d/%d",
+			player.X, player.Y, t[0], t[1], player.HP, player.MaxHP)
+		msg += fmt.Sprintf("\nitems %d  bad %d  enemies %d  solids %d  collected %d",
+			len(md.Items), len(md.BadItems), len(md.Enemies), len(md.SolidTiles), md.Collected)
+	}
+	ebitenutil.DebugPrintAt(screen, msg, 10, screen.Bounds().Dy()-80)
+}
+
+// drawGrid outlines every tile in view.
+func (c *Camera) drawGrid(screen *ebiten.Image, md *MapData) {
+	sw, sh := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+	left, top := c.ScreenToWorld(0, 0)
+	right, bottom := c.ScreenToWorld(sw, sh)
+	for x := int(left) / md.TileW * md.TileW; x <= int(right); x += md.TileW {
+		px, _ := c.WorldToScreen(float64(x), 0)
+		vector.StrokeLine(screen, float32(px), 0, float32(px), float32(sh), 1, gridDebugColor, false)
+	}
+	for y := int(top) / md.TileH * md.TileH; y <= int(bottom); y += md.TileH {
+		_, py := c.WorldToScreen(0, float64(y))
+		vector.StrokeLine(screen, 0, float32(py), float32(sw), float32(py), 1, gridDebugColor, false)
+	}
+}
+
+// strokeShapes outlines world-space shapes on the screen as the last
+// Camera.Draw placed them.
+func (c *Camera) strokeShapes(screen *ebiten.Image, shapes []resolv.IShape, clr color.Color) {
+	toScreen := func(v resolv.Vector) (float32, float32) {
+		x, y := c.WorldToScreen(v.X, v.Y)
+		return float32(x), float32(y)
+	}
+
+	for _, s := range shapes {
+		switch sh := s.(type) {
+		case *resolv.ConvexPolygon:
+			pts := sh.Transformed()
+			for i := range pts {
+				x0, y0 := toScreen(pts[i])
+				x1, y1 := toScreen(pts[(i+1)%len(pts)])
+				vector.StrokeLine(screen, x0, y0, x1, y1, 1, clr, false)
+			}
+		default:
+			b := s.Bounds()
+			x0, y0 := toScreen(b.Min)
+			x1, y1 := toScreen(b.Max)
+			vector.StrokeRect(screen, x0, y0, x1-x0, y1-y0, 1, clr, false)
+		}
+	}
+}
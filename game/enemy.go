@@ -1,72 +1,251 @@
 package game
 
 import (
-	"bytes"
-	"image"
 	"log"
+	"math"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/solarlune/resolv"
+)
+
+// Enemy behaviours. Every behaviour except idle switches to chasing
+// while the player is in line of sight.
+const (
+	BehaviourIdle   = "idle"
+	BehaviourWander = "wander"
+	BehaviourPatrol = "patrol"
+)
+
+const (
+	enemySpeed      = 1.2
+	enemyChaseSpeed = 1.8
+	enemySightRange = 8  // tiles
+	enemyRepath     = 20 // ticks between chase path updates
+	enemyLoseSight  = 90 // ticks of chasing after the player is out of sight
+	enemyBoxW       = 20.0
+	enemyBoxH       = 20.0
+)
+
+// Routes for patrol enemies that were placed at random.
+const (
+	enemyPatrolRange  = 6 // tiles from its spawn a random patrol may go
+	enemyPatrolPoints = 3 // waypoints besides the spawn
 )
 
 // Enemy represents one animated enemy on the map
 type Enemy struct {
-	X, Y   float64
-	Frame  int
-	Images []*ebiten.Image
+	X, Y float64
+	Anim *Animation
+
+	Box           resolv.IShape
+	HitboxOffsetX float64
+	HitboxOffsetY float64
+
+	Behaviour string
+	Waypoints [][2]int // patrol route, in tiles
+
+	waypoint    int
+	path        [][2]int
+	waitTimer   int // wander pause
+	repathTimer int
+	chaseTimer  int // > 0 while chasing
 }
 
-func (e *Enemy) Update() {
-	e.Frame++
+// newEnemy places an enemy with its sprite's top-left at (x, y).
+func newEnemy(x, y float64, sprites *AnimationSet, behaviour string) *Enemy {
+	e := &Enemy{
+		X:         x,
+		Y:         y,
+		Anim:      NewAnimation(sprites),
+		Behaviour: behaviour,
+	}
+	if e.Behaviour == "" {
+		e.Behaviour = BehaviourWander
+	}
+
+	// Same convention as the player: the box sits at the sprite's center
+	// shifted half a tile up-left, which is where the wall boxes are too.
+	w, h := sprites.FrameSize()
+	e.HitboxOffsetX = float64(w)/2 - 16
+	e.HitboxOffsetY = float64(h)/2 - 16
+	e.Box = resolv.NewRectangle(x+e.HitboxOffsetX, y+e.HitboxOffsetY, enemyBoxW, enemyBoxH)
+	return e
 }
 
-// LoadEnemySprites loads and splits enemies.png (1 row, 6 columns)
-// LoadEnemySprites loads and splits enemies.png (1 row, 6 columns)
-func LoadEnemySprites() []*ebiten.Image {
-	data, err := EmbeddedFS.ReadFile("Assets/Sprites/enemies.png")
-	if err != nil {
-		log.Fatalf("Could not load enemy sprite sheet: %v", err)
+// center is the middle of the sprite in world space.
+func (e *Enemy) center() (float64, float64) {
+	return e.X + e.HitboxOffsetX + 16, e.Y + e.HitboxOffsetY + 16
+}
+
+func (e *Enemy) tile(md *MapData) [2]int {
+	cx, cy := e.center()
+	x, y := md.TileAt(cx, cy)
+	return [2]int{x, y}
+}
+
+// -------------------------------
+// AI
+// -------------------------------
+func (e *Enemy) Update(md *MapData, player *Player) {
+	e.Anim.Update(tickMillis())
+	if md == nil || e.Behaviour == BehaviourIdle {
+		return
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		log.Fatalf("Could not decode enemy sprite sheet: %v", err)
+	here := e.tile(md)
+	target := player.Tile(md)
+
+	speed := enemySpeed
+	if manhattan(here, target) <= enemySightRange && md.LineOfSight(here, target) {
+		e.chaseTimer = enemyLoseSight
 	}
-	sheet := ebiten.NewImageFromImage(img)
 
-	const cols = 6
-	frameW := sheet.Bounds().Dx() / cols
-	frameH := sheet.Bounds().Dy()
+	// Close enough to touch: go straight for the player
+	if e.chaseTimer > 0 && manhattan(here, target) <= 1 {
+		e.chaseTimer--
+		e.path = nil
+		e.steerTowards(player.X+player.HitboxOffsetX, player.Y+player.HitboxOffsetY, enemyChaseSpeed, md)
+		return
+	}
+
+	if e.chaseTimer > 0 {
+		e.chaseTimer--
+		speed = enemyChaseSpeed
+		e.repathTimer--
+		if e.repathTimer <= 0 || len(e.path) == 0 {
+			e.path = md.FindPath(here, target)
+			e.repathTimer = enemyRepath
+		}
+		if e.chaseTimer == 0 {
+			e.path = nil // lost the player, go back to the normal routine
+		}
+	} else if len(e.path) == 0 {
+		e.planRoute(md, here)
+	}
+
+	e.followPath(md, speed)
+}
+
+// planRoute picks the next destination for the non-chasing behaviours.
+func (e *Enemy) planRoute(md *MapData, here [2]int) {
+	switch e.Behaviour {
+	case BehaviourPatrol:
+		// a patrol without a route would stand still, so it wanders
+		if len(e.Waypoints) == 0 {
+			e.wander(md, here)
+			return
+		}
+		if here == e.Waypoints[e.waypoint] {
+			e.waypoint = (e.waypoint + 1) % len(e.Waypoints)
+		}
+		e.path = md.FindPath(here, e.Waypoints[e.waypoint])
 
-	// Your original fallback for uneven division
-	if sheet.Bounds().Dx()%cols != 0 {
-		frameW = 19
+	case BehaviourWander:
+		e.wander(md, here)
 	}
+}
 
-	frames := make([]*ebiten.Image, cols)
+// wander pauses for a moment, then heads to a random nearby tile.
+func (e *Enemy) wander(md *MapData, here [2]int) {
+	if e.waitTimer > 0 {
+		e.waitTimer--
+		return
+	}
+	e.waitTimer = 30 + md.aiRNG.IntN(60)
+	dest := [2]int{here[0] + md.aiRNG.IntN(9) - 4, here[1] + md.aiRNG.IntN(9) - 4}
+	e.path = md.FindPath(here, dest)
+}
 
-	for col := 0; col < cols; col++ {
-		sx := col * frameW
-		sy := 0
-		ex := sx + frameW
-		if ex > sheet.Bounds().Dx() {
-			ex = sheet.Bounds().Dx()
+// patrolRoute makes a loop for a randomly placed patrol enemy: its own
+// tile plus a few reachable tiles close by.
+func patrolRoute(md *MapData, start [2]int) [][2]int {
+	var near [][2]int
+	for _, t := range md.ReachableTiles(start[0], start[1]) {
+		if t != start && tileDist(t, start) <= enemyPatrolRange && !md.IsPartialTile(t[0], t[1]) {
+			near = append(near, t)
 		}
-		ey := sy + frameH
+	}
+	if len(near) == 0 {
+		return nil
+	}
 
-		sub := sheet.SubImage(image.Rect(sx, sy, ex, ey)).(*ebiten.Image)
+	route := [][2]int{start}
+	for _, i := range md.aiRNG.Perm(len(near))[:min(enemyPatrolPoints, len(near))] {
+		route = append(route, near[i])
+	}
+	return route
+}
 
-		// --- SCALE ENEMY SPRITE (add this) ---
-		scale := 2.0 // change to 1.5 or 3.0 if needed
-		scaledW := int(float64(ex-sx) * scale)
-		scaledH := int(float64(ey-sy) * scale)
+// followPath walks toward the center of the next tile on the path.
+func (e *Enemy) followPath(md *MapData, speed float64) {
+	if len(e.path) == 0 {
+		return
+	}
+	next := e.path[0]
+	tx := float64(next[0]*md.TileW + md.TileW/2)
+	ty := float64(next[1]*md.TileH + md.TileH/2)
+	cx, cy := e.center()
 
-		scaled := ebiten.NewImage(scaledW, scaledH)
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Scale(scale, scale)
-		scaled.DrawImage(sub, op)
+	dx, dy := tx-cx, ty-cy
+	if math.Abs(dx) <= speed && math.Abs(dy) <= speed {
+		e.move(dx, dy, md)
+		e.path = e.path[1:]
+		return
+	}
 
-		frames[col] = scaled
+	// one axis at a time keeps the enemy on the tile grid
+	if math.Abs(dx) > speed {
+		dx = math.Copysign(speed, dx)
+		dy = 0
+	} else {
+		dy = math.Copysign(speed, dy)
+	}
+	if !e.move(dx, dy, md) {
+		e.path = nil // blocked, plan again next tick
 	}
+}
 
-	return frames
+// steerTowards moves the hitbox straight at a world point.
+func (e *Enemy) steerTowards(x, y, speed float64, md *MapData) {
+	dx := x - (e.X + e.HitboxOffsetX)
+	dy := y - (e.Y + e.HitboxOffsetY)
+	dist := math.Hypot(dx, dy)
+	if dist < 1 {
+		return
+	}
+	if dist > speed {
+		dx, dy = dx/dist*speed, dy/dist*speed
+	}
+	e.move(dx, dy, md)
+}
+
+// move works like Player.move: each axis is tried on its own and
+// undone if it would push the box into a wall. It reports whether the
+// enemy moved at all.
+func (e *Enemy) move(dx, dy float64, md *MapData) bool {
+	moved := false
+	if dx != 0 {
+		e.Box.SetPosition(e.X+dx+e.HitboxOffsetX, e.Y+e.HitboxOffsetY)
+		if !md.CollidesSolid(e.Box) {
+			e.X += dx
+			moved = true
+		}
+	}
+	if dy != 0 {
+		e.Box.SetPosition(e.X+e.HitboxOffsetX, e.Y+dy+e.HitboxOffsetY)
+		if !md.CollidesSolid(e.Box) {
+			e.Y += dy
+			moved = true
+		}
+	}
+	e.Box.SetPosition(e.X+e.HitboxOffsetX, e.Y+e.HitboxOffsetY)
+	return moved
+}
+
+// LoadEnemySprites loads the enemy sheet described by enemies.json.
+func LoadEnemySprites() *AnimationSet {
+	set, err := LoadAnimationSet("Assets/Sprites/enemies.json")
+	if err != nil {
+		log.Fatalf("Could not load enemy sprite sheet: %v", err)
+	}
+	return set
 }
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lafriks/go-tiled"
+)
+
+const entityLayerName = "entities"
+
+// Object types understood in the "entities" layer.
+const (
+	EntityPlayerSpawn = "player_spawn"
+	EntityGoodItem    = "good_item"
+	EntityBadItem     = "bad_item"
+	EntityPortal      = "portal"
+	EntityEnemy       = "enemy"
+)
+
+// MapEntity is one hand-placed object from the map's entities layer.
+type MapEntity struct {
+	Type  string
+	Name  string
+	X, Y  float64
+	Props tiled.Properties
+}
+
+// -------------------------------
+// Read the "entities" object layer
+// -------------------------------
+func (md *MapData) loadEntities() {
+	for _, group := range md.Map.ObjectGroups {
+		if group.Name != entityLayerName {
+			continue
+		}
+		md.HasEntityLayer = true
+
+		for _, obj := range group.Objects {
+			typ := objectType(obj)
+
+			// Tile objects are anchored at their bottom-left corner
+			y := obj.Y
+			if obj.GID != 0 {
+				y -= obj.Height
+			}
+
+			md.Entities = append(md.Entities, MapEntity{
+				Type:  typ,
+				Name:  obj.Name,
+				X:     obj.X + float64(group.OffsetX),
+				Y:     y + float64(group.OffsetY),
+				Props: obj.Properties,
+			})
+		}
+	}
+}
+
+// objectType reads an object's type; Tiled 1.9+ writes "class", older
+// versions write "type".
+func objectType(obj *tiled.Object) string {
+	if obj.Class != "" {
+		return obj.Class
+	}
+	return obj.Type
+}
+
+// PlayerSpawn returns the hand-placed player spawn, if the map has one.
+func (md *MapData) PlayerSpawn() *SpawnPoint {
+	for _, e := range md.Entities {
+		if e.Type == EntityPlayerSpawn {
+			return &SpawnPoint{X: e.X, Y: e.Y}
+		}
+	}
+	return nil
+}
+
+// spawnFromEntities places items, the portal and enemies exactly where the
+// level designer put them instead of picking random tiles.
+func (md *MapData) spawnFromEntities() {
+	if !md.loadItemImages() {
+		return
+	}
+
+	var enemies []MapEntity
+	for _, e := range md.Entities {
+		switch e.Type {
+		case EntityPlayerSpawn:
+			// handled by Game.LoadLevel
+		case EntityGoodItem:
+			md.addItem(e.X, e.Y)
+			md.reserveAt(e.X, e.Y, true)
+		case EntityBadItem:
+			md.addBadItem(e.X, e.Y)
+			md.reserveAt(e.X, e.Y, true)
+		case EntityPortal:
+			md.PortalSpawn = &SpawnPoint{X: e.X, Y: e.Y}
+			md.reserveAt(e.X, e.Y, true)
+		case EntityEnemy:
+			enemies = append(enemies, e)
+			md.reserveAt(e.X, e.Y, false)
+		default:
+			log.Printf(" Unknown entity type %q (object %q) ignored", e.Type, e.Name)
+		}
+	}
+
+	if len(enemies) > 0 {
+		sprites := LoadEnemySprites()
+		for _, e := range enemies {
+			enemy := newEnemy(e.X, e.Y, sprites, e.Props.GetString("behaviour"))
+			enemy.Anim.SetFrame(e.Props.GetInt("start_frame"))
+			enemy.Waypoints = parseWaypoints(e.Props.GetString("waypoints"))
+			md.Enemies = append(md.Enemies, enemy)
+		}
+	}
+}
+
+// parseWaypoints reads a patrol route written as "x,y;x,y;..." in tiles.
+func parseWaypoints(s string) [][2]int {
+	var out [][2]int
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var x, y int
+		if _, err := fmt.Sscanf(part, "%d,%d", &x, &y); err != nil {
+			log.Printf(" Bad waypoint %q: %v", part, err)
+			continue
+		}
+		out = append(out, [2]int{x, y})
+	}
+	return out
+}
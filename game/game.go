@@ -2,10 +2,12 @@ package game
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"log"
+	"math/rand/v2"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	text "github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -13,15 +15,17 @@ import (
 	"golang.org/x/image/font/opentype"
 )
 
+type GameState int
+
 const (
-	ScreenCenterX           = 400.0
-	ScreenCenterY           = 400.0
-	StatePlaying  GameState = iota
+	StateTitle GameState = iota
+	StatePlaying
+	StatePaused
+	StateOptions
+	StateLevelComplete
 	StateGameOver
 )
 
-type GameState int
-
 // -------------------------------
 // FloatText structure
 // -------------------------------
@@ -39,28 +43,63 @@ type Game struct {
 	MapData        *MapData
 	Player         *Player
 	Camera         *Camera
-	screenW        int
+	screenW        int // logical screen size, follows the window via Layout
 	screenH        int
+	zoom           float64
+	pixelPerfect   bool
 	level          int
+	levels         *LevelManifest
+	levelDef       *LevelDef
+	Seed           uint64
+	fixedSeed      bool // seed came from -seed or the manifest, keep it on restart
+	Input          InputSource
+	Tick           int // Update calls since start
+	recorder       *RecordingInput
+	noSaves        bool   // recording or replaying: saves are neither read nor written
+	keys           uint64 // tracked keys held this tick
+	prevKeys       uint64 // tracked keys held last tick
+	notice         string // short status line, e.g. "Game saved"
+	noticeTimer    int
+	quit           bool
 	floatTexts     []*FloatText
 	smallFont      font.Face
 	State          GameState
 	GameOverPlayer *Player
 	Heart          *Heart
+	hudHeart       *ebiten.Image
+	showSolids     bool // F4: outline the collision boxes
+	showDebug      bool // F3: hitboxes, tile grid and stats
+	minimap        *Minimap
+	showMinimap    bool // Tab
+	audio          *Audio
 
 	// --- Portal popup animation ---
 	portalTextTimer int
 	portalAlpha     float64
 	portalY         float64
+
+	// --- Menus ---
+	titleMenu     *Menu
+	pauseMenu     *Menu
+	optionsMenu   *Menu
+	completeMenu  *Menu
+	optionsReturn GameState // state to go back to when leaving Options
 }
 
 // -------------------------------
 // Init Game
 // -------------------------------
-func NewGame() *Game {
+func NewGame(cfg Config) *Game {
 	g := &Game{
-		screenW: 800,
-		screenH: 800,
+		screenW:     800,
+		screenH:     800,
+		zoom:        1,
+		showMinimap: true,
+		Input:       cfg.Input,
+		audio:       NewAudio(cfg.Silent),
+	}
+	if g.Input == nil {
+		g.Input = KeyboardInput{}
 	}
 
 	InitFont()
@@ -78,35 +117,230 @@ func NewGame() *Game {
 	})
 
 	// Initial map + player
-	g.MapData = LoadMap()
-	g.Player = NewPlayer(float64(g.MapData.Width/2-16), float64(g.MapData.Height/2-16))
-	g.Camera = NewCamera(400, 400)
-	g.level = 1
+	g.levels = LoadLevelManifest()
+	g.initSeed(cfg.Seed)
+	if cfg.Record {
+		// a recording is only replayable if restarts reuse the seed
+		g.fixedSeed = true
+		g.recorder = NewRecordingInput(g.Input, g.Seed)
+		g.Input = g.recorder
+	}
+	if _, ok := cfg.Input.(*ReplayInput); ok || cfg.Record {
+		g.noSaves = true
+	}
+	g.Player = NewPlayer(0, 0)
+	g.LoadLevel(g.levels.First)
+	g.initMenus()
+	g.State = StateTitle
+
+	if cfg.Continue && g.noSaves {
+		log.Printf("⚠️ -continue is ignored while recording or replaying")
+	} else if cfg.Continue && HasSave() {
+		if err := g.Load(); err != nil {
+			log.Printf("⚠️ Could not continue saved game: %v", err)
+			g.RestartGame()
+		}
+	}
 
 	return g
 }
 
 // -------------------------------
-// LoadLevel (RESTORED)
+// LoadLevel (driven by the level manifest)
 // -------------------------------
 func (g *Game) LoadLevel(level int) {
-	switch level {
-	case 1:
-		g.MapData = LoadMapFile("Assets/Maps/floor1.tmx")
-	case 2:
-		g.MapData = LoadMapFile("Assets/Maps/floor2.tmx")
-		g.Player.X = 160
-		g.Player.Y = 280
-		g.MapData.Items = nil
-		g.MapData.BadItems = nil
-		g.MapData.Portal = nil
-		g.MapData.SpawnEnemies(2)
-	default:
+	def := g.levels.Level(level)
+	if def == nil {
 		log.Fatalf("Unknown level: %d", level)
 	}
+	g.level = level
+	g.levelDef = def
+
+	g.MapData = LoadMapFile(def.Map)
+	g.MapData.rng = g.levelRNG(level)
+	g.MapData.aiRNG = g.streamRNG(level, aiStream)
+
+	// Spawn priority: hand-placed object → manifest → map center
+	if spawn := g.MapData.PlayerSpawn(); spawn != nil {
+		g.Player.X = spawn.X
+		g.Player.Y = spawn.Y
+	} else if def.Spawn != nil {
+		g.Player.X = def.Spawn.X
+		g.Player.Y = def.Spawn.Y
+	} else {
+		g.Player.X = float64(g.MapData.Width/2 - 16)
+		g.Player.Y = float64(g.MapData.Height/2 - 16)
+	}
+
+	// Random content only goes on tiles the player can walk to
+	spawn := g.Player.Tile(g.MapData)
+	if err := g.MapData.initPlacement(spawn[0], spawn[1], def.Placement()); err != nil {
+		log.Printf("⚠️ Level %d placement: %v", level, err)
+	}
+
+	// Random placement is only a fallback for maps without an entities layer
+	if g.MapData.HasEntityLayer {
+		g.MapData.spawnFromEntities()
+	} else {
+		if err := g.MapData.spawnItems(def.GoodItems, def.BadItems); err != nil {
+			log.Printf("⚠️ Level %d items: %v", level, err)
+		}
+		if err := g.MapData.SpawnEnemies(def.Enemies, def.EnemyBehaviour); err != nil {
+			log.Printf("⚠️ Level %d enemies: %v", level, err)
+		}
+	}
 
 	g.Player.Box.SetPosition(g.Player.X+g.Player.HitboxOffsetX, g.Player.Y+g.Player.HitboxOffsetY)
-	g.Camera = NewCamera(400, 400)
+	g.Player.SetLantern(def.Lantern)
+	g.Camera = g.newCamera(cameraView)
+	if def.Darkness > 0 {
+		g.Camera.Lighting = NewLighting(def.Darkness)
+	}
+	g.centerCamera()
+	g.minimap = NewMinimap(g.MapData)
+	g.minimap.Reveal(g.MapData, g.Player.Tile(g.MapData))
+	g.audio.PlayMusic(def.Music)
+}
+
+// newCamera makes a camera with the player's zoom and scaling choices.
+func (g *Game) newCamera(reference float64) *Camera {
+	c := NewCamera(reference, g.screenW, g.screenH)
+	c.Rand = g.streamRNG(g.level, shakeStream)
+	c.Zoom = g.zoom
+	c.PixelPerfect = g.pixelPerfect
+	c.Resize(g.screenW, g.screenH)
+	return c
+}
+
+// centerCamera puts the camera straight on the player, skipping the
+// smoothed follow.
+func (g *Game) centerCamera() {
+	cx, cy := g.Player.Center()
+	g.Camera.CenterOn(cx, cy, g.MapData.Width, g.MapData.Height)
+}
+
+// -------------------------------
+// Seeded randomness
+// -------------------------------
+func (g *Game) initSeed(seed uint64) {
+	if seed == 0 {
+		seed = g.levels.Seed
+	}
+	g.fixedSeed = seed != 0
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	g.Seed = seed
+}
+
+// levelRNG gives every level its own stream so a level's layout only
+// depends on the seed, not on what happened in earlier levels.
+func (g *Game) levelRNG(level int) *rand.Rand {
+	return rand.New(rand.NewPCG(g.Seed, uint64(level)))
+}
+
+// Extra per-level streams for randomness drawn a varying number of times
+// (enemy AI, camera shake), so it can't shift the layout stream that
+// places items, enemies and the portal.
+const (
+	aiStream    = 1 << 32
+	shakeStream = 2 << 32
+)
+
+// streamRNG is levelRNG on another stream.
+func (g *Game) streamRNG(level int, stream uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(g.Seed, stream|uint64(level)))
+}
+
+// SaveReplay writes everything recorded so far to path.
+func (g *Game) SaveReplay(path string) error {
+	if g.recorder == nil {
+		return errors.New("game was not started with recording enabled")
+	}
+	return g.recorder.Replay().SaveFile(path)
+}
+
+// -------------------------------
+// Key edges + status notice
+// -------------------------------
+func (g *Game) updateKeys() {
+	g.prevKeys = g.keys
+	g.keys = keyMask(g.Input)
+}
+
+// justPressed is true only on the tick a tracked key goes down.
+func (g *Game) justPressed(key ebiten.Key) bool {
+	return maskHasKey(g.keys, key) && !maskHasKey(g.prevKeys, key)
+}
+
+func (g *Game) showNotice(msg string) {
+	g.notice = msg
+	g.noticeTimer = 120
+}
+
+// updateZoom handles the zoom keys (= in, - out).
+func (g *Game) updateZoom() {
+	step := 0
+	if g.justPressed(ebiten.KeyEqual) {
+		step = 1
+	}
+	if g.justPressed(ebiten.KeyMinus) {
+		step = -1
+	}
+	if step == 0 {
+		return
+	}
+	g.Camera.ZoomStep(step, g.screenW, g.screenH)
+	g.zoom = g.Camera.Zoom
+	g.showNotice(fmt.Sprintf("Zoom %gx", g.zoom))
+}
+
+// updateMute toggles all sound with M, in any state.
+func (g *Game) updateMute() {
+	if g.justPressed(ebiten.KeyM) {
+		g.audio.Muted = !g.audio.Muted
+		g.showNotice("Sound " + onOff(!g.audio.Muted))
+	}
+}
+
+func (g *Game) setPixelPerfect(on bool) {
+	g.pixelPerfect = on
+	if g.Camera != nil && g.Camera.Reference > 0 {
+		g.Camera.PixelPerfect = on
+		g.Camera.Resize(g.screenW, g.screenH)
+	}
+}
+
+// canLoad reports whether there is a save the player may load. Replays
+// only store keys, so loads are off while recording or playing one back.
+func (g *Game) canLoad() bool {
+	return !g.noSaves && HasSave()
+}
+
+func (g *Game) updateQuickSave() {
+	if g.justPressed(ebiten.KeyF5) {
+		if g.noSaves {
+			// a replay must not overwrite the player's own save
+			g.showNotice("Saving is off in replays")
+		} else if err := g.Save(); err != nil {
+			log.Printf("⚠️ Quick-save failed: %v", err)
+			g.showNotice("Save failed")
+		} else {
+			g.showNotice("Game saved")
+		}
+	}
+	if g.justPressed(ebiten.KeyF9) {
+		if g.noSaves {
+			g.showNotice("Loading is off in replays")
+		} else if !HasSave() {
+			g.showNotice("No save found")
+		} else if err := g.Load(); err != nil {
+			log.Printf("⚠️ Quick-load failed: %v", err)
+			g.showNotice("Load failed")
+		} else {
+			g.showNotice("Game loaded")
+		}
+	}
 }
 
 // -------------------------------
@@ -139,13 +373,13 @@ func (g *Game) updateFloatTexts() {
 	g.floatTexts = active
 }
 
-func (g *Game) drawFloatTexts(screen *ebiten.Image, camX, camY float64) {
+func (g *Game) drawFloatTexts(screen *ebiten.Image) {
 	for _, ft := range g.floatTexts {
 		clr := color.RGBA{255, 255, 255, uint8(ft.Alpha * 255)}
 
 		drawFace := text.NewGoXFace(g.smallFont)
 		opts := &text.DrawOptions{}
-		opts.GeoM.Translate(ft.X-camX, ft.Y-camY)
+		opts.GeoM.Translate(g.Camera.WorldToScreen(ft.X, ft.Y))
 		opts.ColorScale.ScaleWithColor(clr)
 
 		text.Draw(screen, ft.Text, drawFace, opts)
@@ -177,76 +411,166 @@ func (g *Game) updatePortalTextAnimation() {
 // UPDATE
 // -------------------------------
 func (g *Game) Update() error {
+	g.Input.Update()
+	g.updateKeys()
+	g.Tick++
+	if g.noticeTimer > 0 {
+		g.noticeTimer--
+	}
+	if g.Camera != nil {
+		g.Camera.Resize(g.screenW, g.screenH) // the window may have been resized
+	}
+	g.updateMute()
+	g.audio.Update()
+
+	switch g.State {
+	case StateTitle:
+		g.titleMenu.Update(g)
+	case StateOptions:
+		g.updateOptions()
+	case StatePaused:
+		g.updatePaused()
+	case StateLevelComplete:
+		g.completeMenu.Update(g)
+	case StateGameOver:
+		g.updateGameOver()
+	default:
+		g.updatePlaying()
+	}
 
-	// -------- GAME OVER MODE --------
-	if g.State == StateGameOver {
+	if g.quit {
+		return ebiten.Termination
+	}
+	return nil
+}
 
-		g.GameOverPlayer.Update(nil, g.screenW, g.screenH)
+func (g *Game) updateGameOver() {
+	g.updateDebugKeys()
+	g.layoutGameOver() // the window may have been resized
+	g.GameOverPlayer.Update(g.Input, nil, g.screenW, g.screenH)
 
-		heartRect := makeHeartRect(g.Heart.X, g.Heart.Y, g.Heart.Img)
-		if g.GameOverPlayer.Box.IsIntersecting(heartRect) {
-			g.RestartGame()
-		}
+	heartRect := makeHeartRect(g.Heart.X, g.Heart.Y, g.Heart.Img)
+	if g.GameOverPlayer.Box.IsIntersecting(heartRect) {
+		g.RestartGame()
+	}
+}
 
-		return nil
+func (g *Game) updatePlaying() {
+	if g.justPressed(ebiten.KeyEscape) {
+		g.pauseMenu.Selected = 0
+		g.State = StatePaused
+		return
 	}
 
-	// -------- NORMAL UPDATE --------
+	g.updateQuickSave()
+	g.updateDebugKeys()
+	g.updateZoom()
+	if g.justPressed(ebiten.KeyTab) {
+		g.showMinimap = !g.showMinimap
+	}
 	prevCollected := g.MapData.Collected
+	prevHP := g.Player.HP
 
 	// Move player & check items
-	g.Player.Update(g.MapData.SolidTiles, g.MapData.Width, g.MapData.Height)
+	g.Player.Update(g.Input, g.MapData, g.MapData.Width, g.MapData.Height)
 	g.MapData.CheckItemCollection(g.Player, g)
+	if g.State != StatePlaying {
+		return
+	}
 
-	// Detect 9th fish → start popup animation
-	if g.MapData.Collected == 9 && prevCollected != 9 {
+	// Detect final fish → start popup animation
+	goal := g.levelDef.FishGoal()
+	if goal > 0 && g.MapData.Collected == goal && prevCollected != goal {
 		g.portalTextTimer = 90
 		g.portalAlpha = 0
 		g.portalY = g.MapData.PortalTextY
+		if g.MapData.Portal != nil {
+			g.audio.PlaySFX(SfxPortalOpen)
+		}
 	}
 
 	// update effects
 	g.updateFloatTexts()
 	g.updatePortalTextAnimation()
+	g.MapData.UpdateAnimations()
 	for _, e := range g.MapData.Enemies {
-		e.Update()
+		e.Update(g.MapData, g.Player)
+		if e.Box.IsIntersecting(g.Player.Box) {
+			c := e.Box.Position()
+			g.Player.TakeDamage(1, c.X, c.Y)
+			if g.Player.Dead() {
+				g.gameOver("Caught by an enemy")
+				return
+			}
+		}
+	}
+
+	if g.Player.HP < prevHP {
+		g.Camera.Shake(4, 20)
 	}
+	cx, cy := g.Player.Center()
+	g.Camera.Update(cx, cy, g.MapData.Width, g.MapData.Height)
+	g.minimap.Reveal(g.MapData, g.Player.Tile(g.MapData))
 
 	// portal collision
 	if g.MapData.Portal != nil && g.MapData.Portal.Active {
-		portalRect := makePortalRect(g.MapData.Portal.X, g.MapData.Portal.Y, g.MapData.Portal.Img)
-		if g.Player.Box.IsIntersecting(portalRect) {
-			g.LoadLevel(2)
+		if len(g.MapData.Touching(g.Player.Box, TagPortal)) > 0 && g.levelDef.Next != 0 {
+			g.audio.PlaySFX(SfxPortalEnter)
+			g.completeMenu.Selected = 0
+			g.State = StateLevelComplete
 		}
 	}
-
-	return nil
 }
 
 // -------------------------------
 // DRAW
 // -------------------------------
 func (g *Game) Draw(screen *ebiten.Image) {
-
-	// --------- GAME OVER SCREEN ---------
-	if g.State == StateGameOver {
+	switch g.State {
+	case StateTitle:
 		screen.Fill(color.Black)
+		g.titleMenu.Draw(screen, 220)
+	case StateOptions:
+		if g.optionsReturn == StateTitle {
+			screen.Fill(color.Black)
+		} else {
+			g.drawPlaying(screen)
+			drawDim(screen)
+		}
+		g.optionsMenu.Draw(screen, 220)
+	case StatePaused:
+		g.drawPlaying(screen)
+		drawDim(screen)
+		g.pauseMenu.Draw(screen, 220)
+	case StateLevelComplete:
+		g.drawPlaying(screen)
+		drawDim(screen)
+		g.completeMenu.Draw(screen, 220)
+	case StateGameOver:
+		g.drawGameOver(screen)
+	default:
+		g.drawPlaying(screen)
+	}
+}
 
-		g.Camera.Draw(screen, nil, g.GameOverPlayer, g.Heart)
+func (g *Game) drawGameOver(screen *ebiten.Image) {
+	screen.Fill(color.Black)
 
-		drawCenteredText(screen, "GAME OVER", ScoreFont, ScreenCenterY-150, color.White)
-		drawCenteredText(screen, "Touch the Heart to Restart", ScoreFont, ScreenCenterY-90, color.White)
-		return
-	}
+	g.Camera.Draw(screen, nil, g.GameOverPlayer, g.Heart)
+	g.drawDebug(screen, nil, g.GameOverPlayer)
 
-	// --------- NORMAL RENDER ---------
-	g.Camera.Draw(screen, g.MapData, g.Player, nil)
+	centerY := float64(g.screenH) / 2
+	drawCenteredText(screen, "GAME OVER", ScoreFont, centerY-150, color.White)
+	drawCenteredText(screen, "Touch the Heart to Restart", ScoreFont, centerY-90, color.White)
+	drawCenteredText(screen, fmt.Sprintf("Seed: %d", g.Seed), g.smallFont, centerY-50, color.White)
+}
 
-	camX := g.Player.X - float64(g.Camera.W)/2
-	camY := g.Player.Y - float64(g.Camera.H)/2
+func (g *Game) drawPlaying(screen *ebiten.Image) {
+	g.Camera.Draw(screen, g.MapData, g.Player, nil)
+	g.drawDebug(screen, g.MapData, g.Player)
 
 	// Floating +1 text
-	g.drawFloatTexts(screen, camX, camY)
+	g.drawFloatTexts(screen)
 
 	// -------- Animated Portal Popup Text --------
 	if g.portalTextTimer > 0 {
@@ -256,28 +580,57 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		col := color.RGBA{255, 255, 255, uint8(255 * g.portalAlpha)}
 		opts.ColorScale.ScaleWithColor(col)
 
-		opts.GeoM.Translate(
-			g.MapData.PortalTextX-camX,
-			g.portalY-camY-20,
-		)
+		opts.GeoM.Translate(g.Camera.WorldToScreen(g.MapData.PortalTextX, g.portalY-20))
 
 		text.Draw(screen, "A portal has appeared!", drawFace, opts)
 	}
 
 	// -------- HUD (Fish count) --------
 	count := g.MapData.Collected
-	if count > 9 {
-		count = 9
+	msg := fmt.Sprintf("Fish: %d", count)
+	if goal := g.levelDef.FishGoal(); goal > 0 {
+		if count > goal {
+			count = goal
+		}
+		msg = fmt.Sprintf("Fish: %d / %d", count, goal)
 	}
 
-	msg := fmt.Sprintf("Fish: %d / 9", count)
-
 	drawFace := text.NewGoXFace(ScoreFont)
 	opts := &text.DrawOptions{}
 	opts.GeoM.Translate(30, 50)
 	opts.ColorScale.ScaleWithColor(color.White)
 
 	text.Draw(screen, msg, drawFace, opts)
+
+	// -------- HUD (Hearts) --------
+	g.drawHearts(screen)
+
+	if g.showMinimap {
+		g.minimap.Draw(screen, g.MapData, g.Player)
+	}
+
+	// -------- Status notice (save/load) --------
+	if g.noticeTimer > 0 {
+		drawCenteredText(screen, g.notice, g.smallFont, 40, color.White)
+	}
+}
+
+func (g *Game) drawHearts(screen *ebiten.Image) {
+	if g.hudHeart == nil {
+		g.hudHeart = loadHeartImage()
+	}
+	const scale = 2.0
+	step := float64(g.hudHeart.Bounds().Dx())*scale + 6
+
+	for i := 0; i < g.Player.MaxHP; i++ {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(30+float64(i)*step, 100)
+		if i >= g.Player.HP {
+			op.ColorScale.ScaleAlpha(0.25) // lost heart
+		}
+		screen.DrawImage(g.hudHeart, op)
+	}
 }
 
 // -------------------------------
@@ -286,10 +639,11 @@ func (g *Game) Draw(screen *ebiten.Image) {
 func (g *Game) RestartGame() {
 
 	g.State = StatePlaying
-	g.level = 1
-	g.MapData = LoadMap()
-	g.Player = NewPlayer(float64(g.MapData.Width/2-16), float64(g.MapData.Height/2-16))
-	g.Camera = NewCamera(400, 400)
+	if !g.fixedSeed {
+		g.Seed = rand.Uint64()
+	}
+	g.Player = NewPlayer(0, 0)
+	g.LoadLevel(g.levels.First)
 	g.floatTexts = nil
 
 	g.GameOverPlayer = nil
@@ -300,7 +654,12 @@ func (g *Game) RestartGame() {
 }
 
 // -------------------------------
+// Layout makes the logical screen match the window, so resizing shows
+// more or less of the world instead of stretching it.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth > 0 && outsideHeight > 0 {
+		g.screenW, g.screenH = outsideWidth, outsideHeight
+	}
 	return g.screenW, g.screenH
 }
 
@@ -310,7 +669,7 @@ func drawCenteredText(screen *ebiten.Image, msg string, face font.Face, y float6
 	width, _ := text.Measure(msg, drawFace, 0)
 
 	opts := &text.DrawOptions{}
-	opts.GeoM.Translate(ScreenCenterX-(width/2), y)
+	opts.GeoM.Translate(float64(screen.Bounds().Dx())/2-width/2, y)
 	opts.ColorScale.ScaleWithColor(col)
 
 	text.Draw(screen, msg, drawFace, opts)
@@ -319,24 +678,55 @@ func drawCenteredText(screen *ebiten.Image, msg string, face font.Face, y float6
 // -------------------------------
 // Game Over Objects
 // -------------------------------
-func (g *Game) initGameOverHeart() {
+
+// Game-over layout, as shares of the screen height; the text sits just
+// above the middle.
+const (
+	gameOverHeartY  = 0.68
+	gameOverPlayerY = 0.85
+)
+
+func loadHeartImage() *ebiten.Image {
 	data, err := EmbeddedFS.ReadFile("Assets/Sprites/heart.png")
 	if err != nil {
 		log.Fatal("Could not load heart.png:", err)
 	}
 
 	img, _, _ := image.Decode(bytes.NewReader(data))
-	heartImg := ebiten.NewImageFromImage(img)
+	return ebiten.NewImageFromImage(img)
+}
 
-	g.Heart = &Heart{
-		X:   ScreenCenterX - float64(heartImg.Bounds().Dx())/2,
-		Y:   ScreenCenterY + 325,
-		Img: heartImg,
-	}
+func (g *Game) initGameOverHeart() {
+	g.Heart = &Heart{Img: loadHeartImage()}
+}
+
+func (g *Game) gameOver(reason string) {
+	g.State = StateGameOver
+	g.initGameOverPlayer()
+	g.audio.PlayMusic("")
+	g.audio.PlaySFX(SfxGameOver)
+	log.Printf("💀 %s — GAME OVER", reason)
 }
 
 func (g *Game) initGameOverPlayer() {
 	g.initGameOverHeart()
-	g.GameOverPlayer = NewLanternPlayer(ScreenCenterX, g.Heart.Y+140)
-	g.Camera = NewCamera(g.screenW, g.screenH)
+	g.GameOverPlayer = NewLanternPlayer(float64(g.screenW)/2, float64(g.screenH)*gameOverPlayerY)
+	g.layoutGameOver()
+	g.Camera = NewCamera(0, g.screenW, g.screenH)
+}
+
+// layoutGameOver puts the heart below the text at a fixed share of the
+// screen height and keeps the player inside the screen, so the heart
+// stays reachable at any window size.
+func (g *Game) layoutGameOver() {
+	w, h := float64(g.screenW), float64(g.screenH)
+	hb := g.Heart.Img.Bounds()
+	g.Heart.X = w/2 - float64(hb.Dx())/2
+	g.Heart.Y = min(h*gameOverHeartY, h-float64(hb.Dy()))
+
+	// same bounds as Player.move
+	p := g.GameOverPlayer
+	p.X = max(min(p.X, w-32), 0)
+	p.Y = max(min(p.Y, h-32), 0)
+	p.syncBox()
 }
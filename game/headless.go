@@ -0,0 +1,25 @@
+package game
+
+import "fmt"
+
+// NewHeadlessGame builds a Game that is driven by cfg.Input instead of
+// the keyboard and never needs ebiten.RunGame. Use Step to advance it.
+func NewHeadlessGame(cfg Config) *Game {
+	if cfg.Input == nil {
+		cfg.Input = NewScriptedInput()
+	}
+	cfg.Silent = true
+	g := NewGame(cfg)
+	g.State = StatePlaying // skip the title screen
+	return g
+}
+
+// Step runs Game.Update n times, stopping at the first error.
+func (g *Game) Step(n int) error {
+	for i := 0; i < n; i++ {
+		if err := g.Update(); err != nil {
+			return fmt.Errorf("tick %d: %w", g.Tick, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// floor1's centre spawn sits in the corridor between the two middle
+// walls, so the test starts the player in the open room to the left.
+const (
+	testStartX = 96
+	testStartY = 300
+)
+
+func TestHeadlessWalkRightCollectsFish(t *testing.T) {
+	g := NewHeadlessGame(Config{
+		Seed:  42,
+		Input: NewScriptedInput(Hold(40, ebiten.KeyRight)),
+	})
+	if g.levelDef.Map != "Assets/Maps/floor1.tmx" {
+		t.Fatalf("first level is %s, want floor1", g.levelDef.Map)
+	}
+
+	md := g.MapData
+	p := g.Player
+	p.X, p.Y = testStartX, testStartY
+	p.Box.SetPosition(p.X+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
+
+	// swap the random items for one fish two tiles ahead, on the
+	// player's row, so nothing else can be picked up or hurt them
+	md.clearItems()
+	c := p.Box.Position()
+	iw, ih := float64(md.itemImg.Bounds().Dx()), float64(md.itemImg.Bounds().Dy())
+	md.addItem(c.X+2*float64(md.TileW)-(iw-itemBoxW)/2, c.Y-(ih-itemBoxH)/2)
+
+	if err := g.Step(40); err != nil {
+		t.Fatal(err)
+	}
+
+	if md.Collected != 1 {
+		t.Errorf("Collected = %d, want 1", md.Collected)
+	}
+	if len(md.Items) != 0 {
+		t.Errorf("%d fish left on the map, want 0", len(md.Items))
+	}
+	if p.X < testStartX+2*float64(md.TileW) {
+		t.Errorf("player X = %.1f, want at least two tiles right of %d", p.X, testStartX)
+	}
+	if p.Y != testStartY {
+		t.Errorf("player Y = %.1f, want %d (walking right only)", p.Y, testStartY)
+	}
+	if g.State != StatePlaying {
+		t.Errorf("state = %d, want StatePlaying", g.State)
+	}
+}
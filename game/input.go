@@ -0,0 +1,130 @@
+package game
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// InputSource is where Player and Game read keys from. Swapping it out
+// lets Game.Update run without a window (tests, headless runs).
+type InputSource interface {
+	// Update advances the source by one tick. Game.Update calls it once
+	// before anything reads keys.
+	Update()
+	IsKeyPressed(key ebiten.Key) bool
+}
+
+// -------------------------------
+// Keyboard (the real window)
+// -------------------------------
+type KeyboardInput struct{}
+
+func (KeyboardInput) Update() {}
+
+func (KeyboardInput) IsKeyPressed(key ebiten.Key) bool {
+	return ebiten.IsKeyPressed(key)
+}
+
+// -------------------------------
+// Scripted input
+// -------------------------------
+
+// ScriptStep holds Keys down for Ticks updates.
+type ScriptStep struct {
+	Keys  []ebiten.Key
+	Ticks int
+}
+
+// Hold is a step that keeps keys pressed for the given number of ticks.
+func Hold(ticks int, keys ...ebiten.Key) ScriptStep {
+	return ScriptStep{Keys: keys, Ticks: ticks}
+}
+
+// Wait is a step with nothing pressed.
+func Wait(ticks int) ScriptStep {
+	return ScriptStep{Ticks: ticks}
+}
+
+// ScriptedInput plays back a fixed list of steps; once they run out
+// no keys are pressed.
+type ScriptedInput struct {
+	steps []ScriptStep
+	tick  int // ticks elapsed; -1 until the first Update
+}
+
+func NewScriptedInput(steps ...ScriptStep) *ScriptedInput {
+	return &ScriptedInput{steps: steps, tick: -1}
+}
+
+func (s *ScriptedInput) Update() {
+	s.tick++
+}
+
+func (s *ScriptedInput) IsKeyPressed(key ebiten.Key) bool {
+	step := s.current()
+	if step == nil {
+		return false
+	}
+	for _, k := range step.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Done reports whether every step has been played.
+func (s *ScriptedInput) Done() bool {
+	return s.current() == nil
+}
+
+func (s *ScriptedInput) current() *ScriptStep {
+	t := s.tick
+	if t < 0 {
+		t = 0
+	}
+	for i := range s.steps {
+		if t < s.steps[i].Ticks {
+			return &s.steps[i]
+		}
+		t -= s.steps[i].Ticks
+	}
+	return nil
+}
+
+// trackedKeys are the keys the game reads. Replays store exactly these,
+// so any key Game or Player checks must be listed here.
+var trackedKeys = []ebiten.Key{
+	ebiten.KeyLeft,
+	ebiten.KeyRight,
+	ebiten.KeyUp,
+	ebiten.KeyDown,
+	ebiten.KeyF5, // quick-save
+	ebiten.KeyF9, // quick-load
+	ebiten.KeyEnter,
+	ebiten.KeyEscape,
+	ebiten.KeyF4,    // collision boxes
+	ebiten.KeyF3,    // debug overlay
+	ebiten.KeyShift, // run
+	ebiten.KeyEqual, // zoom in
+	ebiten.KeyMinus, // zoom out
+	ebiten.KeyTab,   // minimap
+	ebiten.KeyM,     // mute
+}
+
+// keyMask packs the pressed tracked keys into one bit per key.
+func keyMask(src InputSource) uint64 {
+	var mask uint64
+	for i, k := range trackedKeys {
+		if src.IsKeyPressed(k) {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+func maskHasKey(mask uint64, key ebiten.Key) bool {
+	for i, k := range trackedKeys {
+		if k == key {
+			return mask&(1<<i) != 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"github.com/lafriks/go-tiled"
+)
+
+const levelManifestPath = "Assets/Levels/levels.json"
+
+// -------------------------------
+// Level manifest structures
+// -------------------------------
+type SpawnPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Win condition types.
+const (
+	WinCollect = "collect"
+	WinNone    = "none"
+)
+
+// WinCondition describes what opens the portal on a level.
+// "collect" needs Count good items, "none" never opens a portal.
+type WinCondition struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+type LevelDef struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	Map       string      `json:"map"`
+	Spawn     *SpawnPoint `json:"spawn"` // nil = center of the map
+	GoodItems int         `json:"good_items"`
+	BadItems  int         `json:"bad_items"`
+	Enemies   int         `json:"enemies"`
+	// Behaviour for randomly placed enemies: wander (default), patrol or idle
+	EnemyBehaviour string       `json:"enemy_behaviour"`
+	Win            WinCondition `json:"win"`
+	Next           int          `json:"next"` // 0 = last level
+
+	// Lighting: 0 = fully lit, 1 = black outside lights. Lantern hands
+	// the player a lantern for the level.
+	Darkness float64 `json:"darkness"`
+	Lantern  bool    `json:"lantern"`
+
+	// Background music, e.g. Assets/Audio/floor1.wav ("" = none)
+	Music string `json:"music"`
+
+	// Random placement rules, in tiles (0 = default)
+	MinSpacing int `json:"min_spacing"`
+	SafeRadius int `json:"safe_radius"`
+}
+
+type LevelManifest struct {
+	First  int        `json:"first"`
+	Seed   uint64     `json:"seed"` // 0 = random unless -seed is given
+	Levels []LevelDef `json:"levels"`
+}
+
+// LoadLevelManifest reads the embedded level list.
+func LoadLevelManifest() *LevelManifest {
+	data, err := EmbeddedFS.ReadFile(levelManifestPath)
+	if err != nil {
+		log.Fatalf("Could not load level manifest: %v", err)
+	}
+
+	lm := &LevelManifest{}
+	if err := json.Unmarshal(data, lm); err != nil {
+		log.Fatalf("Could not parse level manifest: %v", err)
+	}
+	if len(lm.Levels) == 0 {
+		log.Fatalf("Level manifest %s has no levels", levelManifestPath)
+	}
+	if lm.First == 0 {
+		lm.First = lm.Levels[0].ID
+	}
+	if err := lm.validate(); err != nil {
+		log.Fatalf("Level manifest %s: %v", levelManifestPath, err)
+	}
+	return lm
+}
+
+// validate catches broken links and paths at start-up instead of when a
+// player first walks through the portal that leads to them.
+func (lm *LevelManifest) validate() error {
+	ids := map[int]bool{}
+	for _, ld := range lm.Levels {
+		if ids[ld.ID] {
+			return fmt.Errorf("duplicate level id %d", ld.ID)
+		}
+		ids[ld.ID] = true
+	}
+	if !ids[lm.First] {
+		return fmt.Errorf("first level %d is not listed", lm.First)
+	}
+	for _, ld := range lm.Levels {
+		if ld.Next != 0 && !ids[ld.Next] {
+			return fmt.Errorf("level %d: next level %d is not listed", ld.ID, ld.Next)
+		}
+		if _, err := fs.Stat(EmbeddedFS, ld.Map); err != nil {
+			return fmt.Errorf("level %d: map %q: %w", ld.ID, ld.Map, err)
+		}
+		if ld.Music != "" {
+			if _, err := fs.Stat(EmbeddedFS, ld.Music); err != nil {
+				return fmt.Errorf("level %d: music %q: %w", ld.ID, ld.Music, err)
+			}
+		}
+		switch ld.EnemyBehaviour {
+		case "", BehaviourIdle, BehaviourWander, BehaviourPatrol:
+		default:
+			return fmt.Errorf("level %d: unknown enemy_behaviour %q", ld.ID, ld.EnemyBehaviour)
+		}
+		if err := ld.validateWin(); err != nil {
+			return fmt.Errorf("level %d: %w", ld.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateWin makes sure a collect goal can be reached: the map has to
+// offer at least Count good items.
+func (ld *LevelDef) validateWin() error {
+	switch ld.Win.Type {
+	case WinNone:
+		return nil
+	case WinCollect:
+	default:
+		return fmt.Errorf("unknown win type %q", ld.Win.Type)
+	}
+	if ld.Win.Count <= 0 {
+		return fmt.Errorf("collect count must be at least 1, got %d", ld.Win.Count)
+	}
+
+	m, err := tiled.LoadFile(ld.Map, tiled.WithFileSystem(EmbeddedFS))
+	if err != nil {
+		return fmt.Errorf("map %q: %w", ld.Map, err)
+	}
+	// hand-placed items replace the random ones, like in LoadLevel
+	total, hasEntities := 0, false
+	for _, group := range m.ObjectGroups {
+		if group.Name != entityLayerName {
+			continue
+		}
+		hasEntities = true
+		for _, obj := range group.Objects {
+			if objectType(obj) == EntityGoodItem {
+				total++
+			}
+		}
+	}
+	if !hasEntities {
+		total = ld.GoodItems
+	}
+	if ld.Win.Count > total {
+		return fmt.Errorf("collect count %d is more than the %d good items on the level", ld.Win.Count, total)
+	}
+	return nil
+}
+
+// Level returns the definition with the given id, or nil if it is not listed.
+func (lm *LevelManifest) Level(id int) *LevelDef {
+	for i := range lm.Levels {
+		if lm.Levels[i].ID == id {
+			return &lm.Levels[i]
+		}
+	}
+	return nil
+}
+
+// FishGoal is the number of good items needed to open the portal (0 if none).
+func (ld *LevelDef) FishGoal() int {
+	if ld.Win.Type == WinCollect {
+		return ld.Win.Count
+	}
+	return 0
+}
+
+// Placement returns the level's placement rules with defaults filled in.
+func (ld *LevelDef) Placement() PlacementRules {
+	rules := PlacementRules{MinSpacing: ld.MinSpacing, SafeRadius: ld.SafeRadius}
+	if rules.MinSpacing == 0 {
+		rules.MinSpacing = defaultMinSpacing
+	}
+	if rules.SafeRadius == 0 {
+		rules.SafeRadius = defaultSafeRadius
+	}
+	return rules
+}
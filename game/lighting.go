@@ -0,0 +1,197 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	lightTexSize  = 128
+	lightRays     = 96  // rays cast for a light's shadow outline
+	lightRayStep  = 4.0 // px between solid-tile checks along a ray
+	lightWallBite = 10  // px a ray carries into the wall it hits, so wall faces light up
+)
+
+var (
+	lanternLight = color.RGBA{255, 200, 130, 255}
+	playerLight  = color.RGBA{200, 200, 220, 255}
+	portalLight  = color.RGBA{190, 110, 255, 255}
+	itemLight    = color.RGBA{110, 200, 255, 255}
+	badItemLight = color.RGBA{255, 90, 80, 255}
+)
+
+// blendMultiply darkens the destination by the source color.
+var blendMultiply = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorZero,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+// Light is a point light in world space.
+type Light struct {
+	X, Y      float64
+	Radius    float64
+	Color     color.RGBA
+	Intensity float64 // 0..1
+}
+
+// -------------------------------
+// Light map
+// -------------------------------
+
+// Lighting darkens a level and lights it back up around light sources.
+// Lights are added to an offscreen light map that starts at the ambient
+// level, and the map is then multiplied over the camera view.
+type Lighting struct {
+	Darkness float64 // 0 = fully lit, 1 = pitch black outside lights
+
+	lightMap *ebiten.Image
+	tex      *ebiten.Image // radial falloff shared by every light
+	verts    []ebiten.Vertex
+	indices  []uint16
+}
+
+func NewLighting(darkness float64) *Lighting {
+	return &Lighting{
+		Darkness: darkness,
+		tex:      newLightTexture(),
+	}
+}
+
+// newLightTexture makes a white disc that fades out toward its edge.
+func newLightTexture() *ebiten.Image {
+	const half = lightTexSize / 2
+	pix := make([]byte, lightTexSize*lightTexSize*4)
+	for y := 0; y < lightTexSize; y++ {
+		for x := 0; x < lightTexSize; x++ {
+			d := math.Hypot(float64(x)+0.5-half, float64(y)+0.5-half) / half
+			v := 0.0
+			if d < 1 {
+				v = (1 - d) * (1 - d)
+			}
+			b := byte(v * 255)
+			i := (y*lightTexSize + x) * 4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = b, b, b, b
+		}
+	}
+	img := ebiten.NewImage(lightTexSize, lightTexSize)
+	img.WritePixels(pix)
+	return img
+}
+
+// Lights gathers this frame's light sources. Flicker runs off the map's
+// animation clock so it doesn't touch the level's random numbers.
+func (l *Lighting) Lights(md *MapData, player *Player) []Light {
+	var lights []Light
+	t := md.animClock / 1000
+
+	if player != nil {
+		cx, cy := player.Center()
+		if player.Lantern {
+			flicker := 1 + 0.05*math.Sin(t*11) + 0.03*math.Sin(t*23+1.3)
+			lights = append(lights, Light{X: cx, Y: cy, Radius: 170 * flicker, Color: lanternLight, Intensity: 0.95})
+		} else {
+			lights = append(lights, Light{X: cx, Y: cy, Radius: 70, Color: playerLight, Intensity: 0.6})
+		}
+	}
+	if p := md.Portal; p != nil && p.Active {
+		pulse := 1 + 0.08*math.Sin(t*3)
+		lights = append(lights, Light{
+			X: p.X + float64(p.Img.Bounds().Dx())/2, Y: p.Y + float64(p.Img.Bounds().Dy())/2,
+			Radius: 120 * pulse, Color: portalLight, Intensity: 0.9,
+		})
+	}
+	for _, it := range md.Items {
+		lights = append(lights, itemGlow(it, itemLight))
+	}
+	for _, it := range md.BadItems {
+		lights = append(lights, itemGlow(it, badItemLight))
+	}
+	return lights
+}
+
+func itemGlow(it PlacedItem, col color.RGBA) Light {
+	return Light{
+		X:      it.X + float64(it.Img.Bounds().Dx())/2,
+		Y:      it.Y + float64(it.Img.Bounds().Dy())/2,
+		Radius: 44, Color: col, Intensity: 0.7,
+	}
+}
+
+// Apply lights the camera view, whose top-left is at (camX, camY).
+func (l *Lighting) Apply(view *ebiten.Image, md *MapData, player *Player, camX, camY float64) {
+	w, h := view.Bounds().Dx(), view.Bounds().Dy()
+	if l.lightMap == nil || l.lightMap.Bounds().Dx() != w || l.lightMap.Bounds().Dy() != h {
+		l.lightMap = ebiten.NewImage(w, h)
+	}
+
+	ambient := uint8(255 * (1 - l.Darkness))
+	l.lightMap.Fill(color.RGBA{ambient, ambient, ambient, 255})
+
+	for _, lt := range l.Lights(md, player) {
+		// skip lights whose glow can't reach the view
+		if lt.X+lt.Radius < camX || lt.X-lt.Radius > camX+float64(w) ||
+			lt.Y+lt.Radius < camY || lt.Y-lt.Radius > camY+float64(h) {
+			continue
+		}
+		l.drawLight(md, lt, camX, camY)
+	}
+
+	op := &ebiten.DrawImageOptions{Blend: blendMultiply}
+	view.DrawImage(l.lightMap, op)
+}
+
+// drawLight adds one light to the light map as a triangle fan cut off
+// where its rays hit walls, so walls cast shadows.
+func (l *Lighting) drawLight(md *MapData, lt Light, camX, camY float64) {
+	const half = lightTexSize / 2
+	r := float32(lt.Color.R) / 255 * float32(lt.Intensity)
+	g := float32(lt.Color.G) / 255 * float32(lt.Intensity)
+	b := float32(lt.Color.B) / 255 * float32(lt.Intensity)
+
+	vertex := func(x, y float64) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX:   float32(x - camX),
+			DstY:   float32(y - camY),
+			SrcX:   float32(half + (x-lt.X)/lt.Radius*half),
+			SrcY:   float32(half + (y-lt.Y)/lt.Radius*half),
+			ColorR: r, ColorG: g, ColorB: b, ColorA: 1,
+		}
+	}
+
+	l.verts = append(l.verts[:0], vertex(lt.X, lt.Y))
+	l.indices = l.indices[:0]
+	for i := 0; i < lightRays; i++ {
+		a := float64(i) / lightRays * 2 * math.Pi
+		d := md.castRay(lt.X, lt.Y, math.Cos(a), math.Sin(a), lt.Radius)
+		l.verts = append(l.verts, vertex(lt.X+math.Cos(a)*d, lt.Y+math.Sin(a)*d))
+	}
+	for i := 1; i <= lightRays; i++ {
+		next := i%lightRays + 1
+		l.indices = append(l.indices, 0, uint16(i), uint16(next))
+	}
+
+	op := &ebiten.DrawTrianglesOptions{
+		Blend:   ebiten.BlendLighter,
+		Address: ebiten.AddressClampToZero,
+	}
+	l.lightMap.DrawTriangles(l.verts, l.indices, l.tex, op)
+}
+
+// castRay walks from (x, y) along (dx, dy) and returns how far light
+// gets before a solid tile stops it, up to maxDist.
+func (md *MapData) castRay(x, y, dx, dy, maxDist float64) float64 {
+	for d := lightRayStep; d < maxDist; d += lightRayStep {
+		tx := int(math.Floor((x + dx*d) / float64(md.TileW)))
+		ty := int(math.Floor((y + dy*d) / float64(md.TileH)))
+		if md.IsSolidTile(tx, ty) {
+			return math.Min(d+lightWallBite, maxDist)
+		}
+	}
+	return maxDist
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Benchmarks that draw need ebiten's game loop: only while it runs do
+// draw calls reach the GPU and images read back. The loop must own the
+// main thread, so TestMain runs the tests on another goroutine and only
+// starts the loop (and opens a window) when a test asks for it through
+// onGameLoop. Everything else stays windowless.
+
+var (
+	loopJobs  = make(chan func())
+	testsDone = make(chan int)
+)
+
+func TestMain(m *testing.M) {
+	go func() { testsDone <- m.Run() }()
+
+	select {
+	case code := <-testsDone:
+		os.Exit(code)
+	case job := <-loopJobs:
+		go func() { os.Exit(<-testsDone) }()
+		if err := ebiten.RunGame(&testLoop{pending: job}); err != nil {
+			log.Fatal(err)
+		}
+		log.Fatal("game loop stopped before the tests finished")
+	}
+}
+
+// onGameLoop runs f inside a game-loop Update and waits for it. f must
+// not call t.Fatal or b.Fatal, which only work on the test's goroutine.
+func onGameLoop(f func()) {
+	done := make(chan struct{})
+	loopJobs <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+}
+
+// testLoop runs queued jobs, one per Update.
+type testLoop struct {
+	pending func()
+}
+
+func (l *testLoop) Update() error {
+	if l.pending != nil {
+		l.pending()
+		l.pending = nil
+	}
+	select {
+	case job := <-loopJobs:
+		job()
+	default:
+	}
+	return nil
+}
+
+func (*testLoop) Draw(*ebiten.Image) {}
+
+func (*testLoop) Layout(int, int) (int, int) {
+	return 320, 240
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// chunkTiles is the width and height of one pre-rendered chunk, in tiles.
+const chunkTiles = 16
+
+// mapChunk is a square piece of the map baked into its own images, so
+// drawing only touches the chunks the camera can see.
+type mapChunk struct {
+	X, Y  float64      // world position of the top-left corner
+	Below []chunkLayer // layers drawn under actors, bottom first
+	Above []chunkLayer // above_actors layers, empty if the map has none
+}
+
+// chunkLayer is a run of layers baked into one image, topped by the
+// animated tiles of its last layer. Animated tiles can't be baked, so a
+// new run starts after every layer that has some; that keeps them under
+// the layers above them.
+type chunkLayer struct {
+	Img   *ebiten.Image // nil if the run has no static tiles here
+	Anims []animatedTile
+}
+
+// buildChunks bakes every chunk of the map up front.
+func (md *MapData) buildChunks(anims map[uint32]*tileAnimation) {
+	m := md.Map
+	md.chunkCols = (m.Width + chunkTiles - 1) / chunkTiles
+	md.chunkRows = (m.Height + chunkTiles - 1) / chunkTiles
+	split := aboveActorsIndex(m)
+
+	for cy := 0; cy < md.chunkRows; cy++ {
+		for cx := 0; cx < md.chunkCols; cx++ {
+			area := image.Rect(cx*chunkTiles, cy*chunkTiles, (cx+1)*chunkTiles, (cy+1)*chunkTiles).
+				Intersect(image.Rect(0, 0, m.Width, m.Height))
+
+			md.chunks = append(md.chunks, &mapChunk{
+				X:     float64(area.Min.X * md.TileW),
+				Y:     float64(area.Min.Y * md.TileH),
+				Below: drawMap(m, m.Layers[:split], area, md.Tiles, anims),
+				Above: drawMap(m, m.Layers[split:], area, md.Tiles, anims),
+			})
+		}
+	}
+}
+
+// visibleChunks returns the chunks overlapping the given world rectangle.
+func (md *MapData) visibleChunks(x, y, w, h float64) []*mapChunk {
+	chunkW := float64(chunkTiles * md.TileW)
+	chunkH := float64(chunkTiles * md.TileH)
+
+	x0 := max(int(x/chunkW), 0)
+	y0 := max(int(y/chunkH), 0)
+	x1 := min(int((x+w)/chunkW), md.chunkCols-1)
+	y1 := min(int((y+h)/chunkH), md.chunkRows-1)
+
+	var out []*mapChunk
+	for cy := y0; cy <= y1; cy++ {
+		for cx := x0; cx <= x1; cx++ {
+			out = append(out, md.chunks[cy*md.chunkCols+cx])
+		}
+	}
+	return out
+}
+
+// drawChunks draws one layer group (under or over actors) of every chunk
+// in view, animated tiles included.
+func (md *MapData) drawChunks(dst *ebiten.Image, camX, camY float64, above bool) {
+	viewW := float64(dst.Bounds().Dx())
+	viewH := float64(dst.Bounds().Dy())
+	md.drawChunkList(dst, md.visibleChunks(camX, camY, viewW, viewH), camX, camY, above)
+}
+
+// drawChunkList draws one layer group of the given chunks.
+func (md *MapData) drawChunkList(dst *ebiten.Image, chunks []*mapChunk, camX, camY float64, above bool) {
+	for _, c := range chunks {
+		layers := c.Below
+		if above {
+			layers = c.Above
+		}
+		for _, cl := range layers {
+			if cl.Img != nil {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(c.X-camX, c.Y-camY)
+				dst.DrawImage(cl.Img, op)
+			}
+			for _, at := range cl.Anims {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(at.X-camX, at.Y-camY)
+				dst.DrawImage(at.Anim.frameAt(md.animClock), op)
+			}
+		}
+	}
+}
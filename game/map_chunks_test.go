@@ -0,0 +1,81 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lafriks/go-tiled"
+)
+
+const largeMapPath = "Assets/Maps/floor1.tmx"
+
+// largeTiledMap parses floor1 and repeats its layers out to w x h tiles,
+// for benchmarks that need a map far bigger than the screen.
+func largeTiledMap(tb testing.TB, w, h int) (*tiled.Map, map[uint32]*ebiten.Image) {
+	tb.Helper()
+	m, err := tiled.LoadFile(largeMapPath, tiled.WithFileSystem(EmbeddedFS))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	loadExternalTilesets(m)
+	tileImages := loadTilesFromEmbed(largeMapPath, m)
+
+	for _, l := range m.Layers {
+		tiles := make([]*tiled.LayerTile, w*h)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				tiles[y*w+x] = l.Tiles[(y%m.Height)*m.Width+x%m.Width]
+			}
+		}
+		l.Tiles = tiles
+	}
+	m.Width, m.Height = w, h
+	return m, tileImages
+}
+
+// BenchmarkChunkDraw compares one frame of map drawing with culling
+// against drawing every chunk of a 200x200 map.
+func BenchmarkChunkDraw(b *testing.B) {
+	md := newMapData(largeTiledMap(b, 200, 200))
+	const viewW, viewH = 400, 400
+	view := ebiten.NewImage(viewW, viewH)
+	camX, camY := float64(md.Width-viewW)/2, float64(md.Height-viewH)/2
+
+	for _, bc := range []struct {
+		name   string
+		chunks []*mapChunk
+	}{
+		{"culled", md.visibleChunks(camX, camY, viewW, viewH)},
+		{"all_chunks", md.chunks},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			onGameLoop(func() {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					view.Clear()
+					md.drawChunkList(view, bc.chunks, camX, camY, false)
+					md.drawChunkList(view, bc.chunks, camX, camY, true)
+					view.At(0, 0) // wait for the GPU so the whole frame is timed
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkCameraDraw times a whole culled frame on the same map.
+func BenchmarkCameraDraw(b *testing.B) {
+	md := newMapData(largeTiledMap(b, 200, 200))
+	player := NewPlayer(float64(md.Width/2), float64(md.Height/2))
+	cam := NewCamera(cameraView, 800, 800)
+	cx, cy := player.Center()
+	cam.CenterOn(cx, cy, md.Width, md.Height)
+	screen := ebiten.NewImage(800, 800)
+
+	onGameLoop(func() {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			cam.Draw(screen, md, player, nil)
+			screen.At(0, 0)
+		}
+	})
+}
@@ -19,22 +19,25 @@ import (
 type PlacedItem struct {
 	X, Y float64
 	Img  *ebiten.Image
+	Box  resolv.IShape // registered in MapData.Space
 }
 type Portal struct {
 	X, Y   float64
-	Img    *ebiten.Image
+	Img    *ebiten.Image // first frame, sizes the hitbox
+	Anim   *Animation
 	Active bool
+	Box    resolv.IShape
 }
 
 type MapData struct {
 	Map         *tiled.Map
-	Image       *ebiten.Image
 	Tiles       map[uint32]*ebiten.Image
 	TileW       int
 	TileH       int
 	Width       int
 	Height      int
 	SolidTiles  []resolv.IShape
+	Space       *resolv.Space // solids, items and the portal, bucketed by tile
 	Items       []PlacedItem
 	BadItems    []PlacedItem
 	Collected   int
@@ -43,6 +46,24 @@ type MapData struct {
 	PortalTextX float64
 	PortalTextY float64
 	Enemies     []*Enemy
+
+	// Hand-placed content from the "entities" object layer
+	Entities       []MapEntity
+	HasEntityLayer bool
+	PortalSpawn    *SpawnPoint // nil = random empty tile
+
+	solidGrid   []bool      // one entry per tile, true if any visible layer is solid there
+	partialGrid []bool      // tiles only partly blocked by collision shapes
+	placer      *Placer     // random placement state for the current level
+	rng         *rand.Rand  // layout, seeded per level by Game.LoadLevel
+	aiRNG       *rand.Rand  // enemy decisions, kept apart so they can't shift the layout
+	chunks      []*mapChunk // pre-rendered map pieces, row by row
+	chunkCols   int
+	chunkRows   int
+	animClock   float64 // ms of tile animation played so far
+
+	itemImg    *ebiten.Image
+	badItemImg *ebiten.Image
 }
 
 var GameOver bool
@@ -126,34 +147,6 @@ func scaleImage(img image.Image, scale float64) *ebiten.Image {
 // -------------------------------
 // Map loading functions
 // -------------------------------
-func LoadMap() *MapData {
-	m, err := tiled.LoadFile("Assets/Maps/floor1.tmx", tiled.WithFileSystem(EmbeddedFS))
-	if err != nil {
-		log.Fatalf("Failed to load map: %v", err)
-	}
-
-	loadExternalTilesets(m)
-	tileImages := loadTilesFromEmbed("Assets/Maps/floor1.tmx", m)
-
-	w := m.Width * m.TileWidth
-	h := m.Height * m.TileHeight
-	img := ebiten.NewImage(w, h)
-	drawMap(img, m, tileImages)
-
-	md := &MapData{
-		Map:    m,
-		Image:  img,
-		Tiles:  tileImages,
-		TileW:  m.TileWidth,
-		TileH:  m.TileHeight,
-		Width:  w,
-		Height: h,
-	}
-	md.loadCollision()
-	md.spawnItems()
-	return md
-}
-
 func loadExternalTilesets(m *tiled.Map) {
 	for _, ts := range m.Tilesets {
 		if ts.Source != "" && ts.Tiles == nil {
@@ -198,13 +191,34 @@ func loadTilesFromEmbed(mapPath string, m *tiled.Map) map[uint32]*ebiten.Image {
 	return result
 }
 
-func drawMap(dst *ebiten.Image, m *tiled.Map, tiles map[uint32]*ebiten.Image) {
-	for _, layer := range m.Layers {
+// aboveActorsIndex is the first layer flagged above_actors=true; it and
+// every layer after it are drawn over the player, enemies and items.
+// It returns len(m.Layers) when no layer is flagged.
+func aboveActorsIndex(m *tiled.Map) int {
+	for i, layer := range m.Layers {
+		if layer.Properties.GetBool("above_actors") {
+			return i
+		}
+	}
+	return len(m.Layers)
+}
+
+// drawMap bakes the static tiles of the given layers inside area (in
+// tiles), with area's top-left corner at each image's origin. Animated
+// tiles are left out and kept with the run of layers they end, so they
+// can be drawn every frame in layer order.
+func drawMap(m *tiled.Map, layers []*tiled.Layer, area image.Rectangle, tiles map[uint32]*ebiten.Image, anims map[uint32]*tileAnimation) []chunkLayer {
+	var out []chunkLayer
+	var run chunkLayer
+	originX := float64(area.Min.X * m.TileWidth)
+	originY := float64(area.Min.Y * m.TileHeight)
+
+	for _, layer := range layers {
 		if !layer.Visible {
 			continue
 		}
-		for y := 0; y < m.Height; y++ {
-			for x := 0; x < m.Width; x++ {
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
 				idx := y*m.Width + x
 				if idx >= len(layer.Tiles) {
 					continue
@@ -214,19 +228,54 @@ func drawMap(dst *ebiten.Image, m *tiled.Map, tiles map[uint32]*ebiten.Image) {
 					continue
 				}
 				gid := tile.Tileset.FirstGID + tile.ID
+				if a := anims[gid]; a != nil {
+					run.Anims = append(run.Anims, animatedTile{
+						X:    float64(x * m.TileWidth),
+						Y:    float64(y * m.TileHeight),
+						Anim: a,
+					})
+					continue
+				}
 				img := tiles[gid]
 				if img == nil {
 					continue
 				}
+				if run.Img == nil {
+					run.Img = ebiten.NewImage(area.Dx()*m.TileWidth, area.Dy()*m.TileHeight)
+				}
 				op := &ebiten.DrawImageOptions{}
-				op.GeoM.Translate(float64(x*m.TileWidth), float64(y*m.TileHeight))
-				dst.DrawImage(img, op)
+				op.GeoM.Translate(float64(x*m.TileWidth)-originX, float64(y*m.TileHeight)-originY)
+				run.Img.DrawImage(img, op)
 			}
 		}
+
+		// later layers go into a new image so they cover these tiles
+		if len(run.Anims) > 0 {
+			out = append(out, run)
+			run = chunkLayer{}
+		}
+	}
+	if run.Img != nil {
+		out = append(out, run)
 	}
+	return out
 }
 
+// loadCollision marks every tile that is solid on any visible layer,
+// then covers the solid cells with as few boxes as it can. Tiles with
+// their own collision shapes add those shapes as they are.
 func (md *MapData) loadCollision() {
+	md.solidGrid = make([]bool, md.Map.Width*md.Map.Height)
+	md.partialGrid = make([]bool, md.Map.Width*md.Map.Height)
+
+	type partial struct {
+		idx  int
+		gid  uint32
+		body []tileBody
+	}
+	var partials []partial
+	seen := map[[2]int]bool{} // (cell, gid) already added from another layer
+
 	for _, layer := range md.Map.Layers {
 		if !layer.Visible {
 			continue
@@ -242,160 +291,203 @@ func (md *MapData) loadCollision() {
 					continue
 				}
 
-				isSolid := false
-				if int(tile.ID) < len(tile.Tileset.Tiles) {
-					tsTile := tile.Tileset.Tiles[int(tile.ID)]
-					if tsTile != nil && tsTile.Properties != nil && tsTile.Properties.GetBool("solid") {
-						isSolid = true
+				col := collisionFor(tilesetTile(tile), md.TileW, md.TileH)
+				if col.Full {
+					md.solidGrid[idx] = true
+				} else if len(col.Shapes) > 0 {
+					gid := tile.Tileset.FirstGID + tile.ID
+					if !seen[[2]int{idx, int(gid)}] {
+						seen[[2]int{idx, int(gid)}] = true
+						partials = append(partials, partial{idx, gid, col.Shapes})
 					}
 				}
-
-				if isSolid {
-					rect := resolv.NewRectangle(float64(x*md.TileW), float64(y*md.TileH), float64(md.TileW), float64(md.TileH))
-					md.SolidTiles = append(md.SolidTiles, rect)
-				}
 			}
 		}
 	}
+
+	for _, r := range mergeSolidCells(md.solidGrid, md.Map.Width, md.Map.Height) {
+		// Boxes keep the old per-tile placement, half a tile up-left of
+		// the art, which is what the player and enemy offsets expect.
+		rect := resolv.NewRectangleFromTopLeft(
+			float64(r.Min.X*md.TileW-md.TileW/2), float64(r.Min.Y*md.TileH-md.TileH/2),
+			float64(r.Dx()*md.TileW), float64(r.Dy()*md.TileH),
+		)
+		md.SolidTiles = append(md.SolidTiles, rect)
+		md.addShape(rect, TagSolid)
+	}
+
+	for _, p := range partials {
+		if md.solidGrid[p.idx] {
+			continue // a fully solid tile on another layer covers it
+		}
+		md.partialGrid[p.idx] = true
+		x := float64((p.idx%md.Map.Width)*md.TileW - md.TileW/2)
+		y := float64((p.idx/md.Map.Width)*md.TileH - md.TileH/2)
+		for _, b := range p.body {
+			s := b.shape(x, y)
+			md.SolidTiles = append(md.SolidTiles, s)
+			md.addShape(s, TagSolid)
+		}
+	}
 }
 
 // -------------------------------
 // Spawn items (good + bad)
 // -------------------------------
-func (md *MapData) spawnItems() {
+// loadItemImages loads the good and bad item sprites once per map.
+func (md *MapData) loadItemImages() bool {
+	if md.itemImg != nil && md.badItemImg != nil {
+		return true
+	}
+
 	data, err := EmbeddedFS.ReadFile("Assets/Sprites/tuna_closed.png")
 	if err != nil {
 		log.Printf("⚠️ Could not load fish item: %v", err)
-		return
+		return false
 	}
 	img, _, _ := image.Decode(bytes.NewReader(data))
-	fishImg := scaleImage(img, 0.2)
-
-	var emptyTiles [][2]int
-	for y := 0; y < md.Map.Height; y++ {
-		for x := 0; x < md.Map.Width; x++ {
-			tileX := float64(x * md.TileW)
-			tileY := float64(y * md.TileH)
-			tileRect := resolv.NewRectangle(tileX, tileY, float64(md.TileW), float64(md.TileH))
-
-			solid := false
-			for _, s := range md.SolidTiles {
-				if tileRect.IsIntersecting(s) {
-					solid = true
-					break
-				}
-			}
-
-			if !solid {
-				emptyTiles = append(emptyTiles, [2]int{x, y})
-			}
-		}
-	}
-	md.EmptyTiles = emptyTiles
-
-	for i := 0; i < 15 && len(emptyTiles) > 0; i++ {
-		idx := rand.IntN(len(emptyTiles))
-		tile := emptyTiles[idx]
-		emptyTiles = append(emptyTiles[:idx], emptyTiles[idx+1:]...)
-
-		md.Items = append(md.Items, PlacedItem{
-			X:   float64(tile[0] * md.TileW),
-			Y:   float64(tile[1] * md.TileH),
-			Img: fishImg,
-		})
-	}
+	md.itemImg = scaleImage(img, 0.2)
 
 	dataBad, err := EmbeddedFS.ReadFile("Assets/Sprites/tuna_open.png")
 	if err != nil {
 		log.Printf(" Could not load bad item image: %v", err)
-		return
+		return false
 	}
 	imgBad, _, _ := image.Decode(bytes.NewReader(dataBad))
-	badImg := scaleImage(imgBad, 0.2)
+	md.badItemImg = scaleImage(imgBad, 0.2)
+	return true
+}
 
-	for i := 0; i < 5 && len(emptyTiles) > 0; i++ {
-		idx := rand.IntN(len(emptyTiles))
-		tile := emptyTiles[idx]
-		emptyTiles = append(emptyTiles[:idx], emptyTiles[idx+1:]...)
+// spawnItems scatters good and bad items over reachable tiles.
+func (md *MapData) spawnItems(goodCount, badCount int) error {
+	if !md.loadItemImages() {
+		return fmt.Errorf("item sprites missing")
+	}
 
-		md.BadItems = append(md.BadItems, PlacedItem{
-			X:   float64(tile[0] * md.TileW),
-			Y:   float64(tile[1] * md.TileH),
-			Img: badImg,
-		})
+	goodTiles, err := md.placeTiles(goodCount, true, "good items")
+	for _, tile := range goodTiles {
+		md.addItem(float64(tile[0]*md.TileW), float64(tile[1]*md.TileH))
+	}
+	if err != nil {
+		return err
 	}
 
-	md.EmptyTiles = emptyTiles
+	badTiles, err := md.placeTiles(badCount, true, "bad items")
+	for _, tile := range badTiles {
+		md.addBadItem(float64(tile[0]*md.TileW), float64(tile[1]*md.TileH))
+	}
+	return err
 }
 
 // -------------------------------
 // Collision + collection
 // -------------------------------
 func (md *MapData) CheckItemCollection(player *Player, g *Game) {
-	var remaining []PlacedItem
+	goal := g.levelDef.FishGoal()
 	var lastCollectedX, lastCollectedY float64
 	collectedThisFrame := false
-	playerBox := player.Box
 
-	for _, item := range md.Items {
-		itemRect := makeItemRect(item.X, item.Y, item.Img)
-		if playerBox.IsIntersecting(itemRect) {
-			if md.Collected < 9 {
+	if hits := md.Touching(player.Box, TagItem); len(hits) > 0 {
+		var remaining []PlacedItem
+		for _, item := range md.Items {
+			if !containsShape(hits, item.Box) {
+				remaining = append(remaining, item)
+				continue
+			}
+			md.removeShape(item.Box)
+			if goal == 0 || md.Collected < goal {
 				md.Collected++
 				collectedThisFrame = true
 				lastCollectedX = item.X
 				lastCollectedY = item.Y
 				g.AddFloatText(player.X+8, player.Y-10)
+				g.audio.PlaySFX(SfxFish)
 			}
-			continue
 		}
-		remaining = append(remaining, item)
+		md.Items = remaining
 	}
-	md.Items = remaining
 
 	if collectedThisFrame {
 		md.PortalTextX = lastCollectedX
 		md.PortalTextY = lastCollectedY
 	}
 
-	if collectedThisFrame && md.Collected == 9 && md.Portal == nil {
-		data, err := EmbeddedFS.ReadFile("Assets/Sprites/portal.png")
-		if err != nil {
-			log.Printf(" Could not load portal image: %v", err)
-			return
-		}
-		img, _, _ := image.Decode(bytes.NewReader(data))
-		portalImg := ebiten.NewImageFromImage(img)
+	if collectedThisFrame && goal > 0 && md.Collected == goal && md.Portal == nil {
+		md.spawnPortal()
+	}
 
-		if len(md.EmptyTiles) == 0 {
-			log.Println("⚠️ No empty tiles available for portal spawn.")
-			return
+	// --- Unified bad item collision ---
+	if hits := md.Touching(player.Box, TagBadItem); len(hits) > 0 {
+		var remainingBad []PlacedItem
+		for _, bad := range md.BadItems {
+			if containsShape(hits, bad.Box) {
+				// the can is used up by the hit; while invulnerable it stays put
+				c := bad.Box.Position()
+				if player.TakeDamage(1, c.X, c.Y) {
+					log.Println("🐟 Hit a bad can")
+					g.audio.PlaySFX(SfxBadCan)
+					md.removeShape(bad.Box)
+					continue
+				}
+			}
+			remainingBad = append(remainingBad, bad)
 		}
+		md.BadItems = remainingBad
+	}
 
-		randomTile := md.EmptyTiles[rand.IntN(len(md.EmptyTiles))]
-		md.Portal = &Portal{
-			X:      float64(randomTile[0] * md.TileW),
-			Y:      float64(randomTile[1] * md.TileH),
-			Img:    portalImg,
-			Active: true,
-		}
-		log.Println(" Portal spawned randomly! Text remains at last collected fish.")
+	if player.Dead() {
+		g.gameOver("Hit a bad can")
 	}
+}
 
-	// --- Unified bad item collision ---
-	var remainingBad []PlacedItem
-	for _, bad := range md.BadItems {
-		badRect := makeBadItemRect(bad.X, bad.Y, bad.Img)
-		if player.Box.IsIntersecting(badRect) {
-			md.BadItems = nil
-			g.State = StateGameOver
-			g.initGameOverPlayer()
-			log.Println("💀 Hit a bad can — GAME OVER")
-			return
+// -------------------------------
+// Portal
+// -------------------------------
+func (md *MapData) newPortal(x, y float64, active bool) *Portal {
+	set, err := LoadAnimationSet("Assets/Sprites/portal.json")
+	if err != nil {
+		log.Printf(" Could not load portal image: %v", err)
+		return nil
+	}
+
+	// the portal plays its opening clip once, then idles
+	anim := NewAnimation(set)
+	anim.Play("open")
+	anim.OnFinish = func(clip string) {
+		if clip == "open" {
+			anim.Play("idle")
 		}
-		remainingBad = append(remainingBad, bad)
 	}
-	md.BadItems = remainingBad
+
+	portalImg := anim.Image()
+	return &Portal{
+		X:      x,
+		Y:      y,
+		Img:    portalImg,
+		Anim:   anim,
+		Active: active,
+		Box:    makePortalRect(x, y, portalImg),
+	}
+}
+
+// spawnPortal opens the portal at its placed position, or on a random
+// reachable tile when the map does not place one.
+func (md *MapData) spawnPortal() {
+	if md.PortalSpawn != nil {
+		md.setPortal(md.newPortal(md.PortalSpawn.X, md.PortalSpawn.Y, true))
+		log.Println(" Portal opened at its placed position.")
+		return
+	}
+
+	tiles, err := md.placeTiles(1, true, "portal")
+	if len(tiles) == 0 {
+		log.Printf("⚠️ No tile available for portal spawn: %v", err)
+		return
+	}
+
+	randomTile := tiles[0]
+	md.setPortal(md.newPortal(float64(randomTile[0]*md.TileW), float64(randomTile[1]*md.TileH), true))
+	log.Println(" Portal spawned randomly! Text remains at last collected fish.")
 }
 
 // -------------------------------
@@ -406,42 +498,42 @@ func LoadMapFile(path string) *MapData {
 	}
 
 	loadExternalTilesets(m)
-	tileImages := loadTilesFromEmbed(path, m)
+	return newMapData(m, loadTilesFromEmbed(path, m))
+}
 
+// newMapData builds the chunks, collision and entities of a parsed map.
+func newMapData(m *tiled.Map, tileImages map[uint32]*ebiten.Image) *MapData {
 	w := m.Width * m.TileWidth
 	h := m.Height * m.TileHeight
-	img := ebiten.NewImage(w, h)
-	drawMap(img, m, tileImages)
 
 	md := &MapData{
 		Map:    m,
-		Image:  img,
 		Tiles:  tileImages,
 		TileW:  m.TileWidth,
 		TileH:  m.TileHeight,
 		Width:  w,
 		Height: h,
 	}
+	md.buildChunks(loadTileAnimations(m, tileImages))
+	md.initSpace()
 	md.loadCollision()
-	md.spawnItems()
+	md.loadEntities()
 	return md
 }
 
-func (md *MapData) SpawnEnemies(count int) {
-	enemyFrames := LoadEnemySprites()
-	if len(md.EmptyTiles) == 0 {
-		log.Println(" No empty tiles available for enemies")
-		return
+func (md *MapData) SpawnEnemies(count int, behaviour string) error {
+	if count <= 0 {
+		return nil
 	}
+	sprites := LoadEnemySprites()
 
-	for i := 0; i < count; i++ {
-		tile := md.EmptyTiles[rand.IntN(len(md.EmptyTiles))]
-		enemy := &Enemy{
-			X:      float64(tile[0]*md.TileW + 8),
-			Y:      float64(tile[1]*md.TileH + 8),
-			Frame:  0,
-			Images: enemyFrames,
+	tiles, err := md.placeTiles(count, false, "enemies")
+	for _, tile := range tiles {
+		enemy := newEnemy(float64(tile[0]*md.TileW+8), float64(tile[1]*md.TileH+8), sprites, behaviour)
+		if enemy.Behaviour == BehaviourPatrol {
+			enemy.Waypoints = patrolRoute(md, enemy.tile(md))
 		}
 		md.Enemies = append(md.Enemies, enemy)
 	}
+	return err
 }
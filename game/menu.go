@@ -0,0 +1,115 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const gameTitle = "Tuna Hunt"
+
+var (
+	menuColor         = color.RGBA{170, 170, 170, 255}
+	menuSelectedColor = color.RGBA{255, 220, 90, 255}
+	menuDisabledColor = color.RGBA{80, 80, 80, 255}
+)
+
+// -------------------------------
+// Menu widget
+// -------------------------------
+
+// MenuItem is one line of a Menu. Value, if set, is shown after the
+// label (for options like "Fullscreen: On"). Enabled, if set, greys the
+// item out and skips it while it returns false. Adjust, if set, is run
+// with -1/+1 on Left/Right (for sliders like volumes).
+type MenuItem struct {
+	Label   string
+	Value   func() string
+	Enabled func() bool
+	Action  func()
+	Adjust  func(step int)
+}
+
+func (it *MenuItem) enabled() bool {
+	return it.Enabled == nil || it.Enabled()
+}
+
+func (it *MenuItem) text() string {
+	if it.Value != nil {
+		return it.Label + ": " + it.Value()
+	}
+	return it.Label
+}
+
+// Menu is a vertical, keyboard-navigable list drawn with ScoreFont.
+type Menu struct {
+	Title    string
+	Items    []MenuItem
+	Selected int
+}
+
+// Update moves the selection with Up/Down, runs the item on Enter and
+// adjusts it with Left/Right.
+func (m *Menu) Update(g *Game) {
+	if len(m.Items) == 0 {
+		return
+	}
+	if !m.Items[m.Selected].enabled() {
+		m.move(1)
+	}
+
+	if g.justPressed(ebiten.KeyUp) {
+		m.move(-1)
+	}
+	if g.justPressed(ebiten.KeyDown) {
+		m.move(1)
+	}
+	it := &m.Items[m.Selected]
+	if !it.enabled() {
+		return
+	}
+	if g.justPressed(ebiten.KeyEnter) && it.Action != nil {
+		it.Action()
+	}
+	if it.Adjust != nil {
+		if g.justPressed(ebiten.KeyLeft) {
+			it.Adjust(-1)
+		}
+		if g.justPressed(ebiten.KeyRight) {
+			it.Adjust(1)
+		}
+	}
+}
+
+// move steps the selection, wrapping and skipping disabled items.
+func (m *Menu) move(step int) {
+	n := len(m.Items)
+	for i := 0; i < n; i++ {
+		m.Selected = (m.Selected + step + n) % n
+		if m.Items[m.Selected].enabled() {
+			return
+		}
+	}
+}
+
+// Draw renders the title and items centered on the screen starting at y.
+func (m *Menu) Draw(screen *ebiten.Image, y float64) {
+	if m.Title != "" {
+		drawCenteredText(screen, m.Title, ScoreFont, y, color.White)
+		y += 90
+	}
+	for i := range m.Items {
+		it := &m.Items[i]
+		col := menuColor
+		label := it.text()
+		switch {
+		case !it.enabled():
+			col = menuDisabledColor
+		case i == m.Selected:
+			col = menuSelectedColor
+			label = "> " + label + " <"
+		}
+		drawCenteredText(screen, label, ScoreFont, y, col)
+		y += 55
+	}
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	minimapSize   = 160 // px, longest side
+	minimapMargin = 20
+	minimapReveal = 5 // tiles around the player that get explored
+)
+
+var (
+	minimapFloor  = [4]byte{70, 70, 80, 255}
+	minimapWall   = [4]byte{170, 170, 180, 255}
+	minimapBack   = color.RGBA{0, 0, 0, 160}
+	minimapPlayer = color.RGBA{90, 255, 90, 255}
+	minimapPortal = color.RGBA{200, 80, 255, 255}
+)
+
+// -------------------------------
+// Minimap with fog of war
+// -------------------------------
+
+// Minimap shows the explored part of the collision grid, one pixel per
+// tile. The pixels only change when new tiles are explored, so the map
+// image is kept and re-uploaded just then.
+type Minimap struct {
+	w, h     int
+	explored []bool
+	pixels   []byte // RGBA, one pixel per tile; unexplored stays transparent
+	img      *ebiten.Image
+	dirty    bool
+	last     [2]int // player tile at the last reveal
+}
+
+func NewMinimap(md *MapData) *Minimap {
+	w, h := md.Map.Width, md.Map.Height
+	return &Minimap{
+		w:        w,
+		h:        h,
+		explored: make([]bool, w*h),
+		pixels:   make([]byte, w*h*4),
+		img:      ebiten.NewImage(w, h),
+		last:     [2]int{-1, -1},
+	}
+}
+
+// Reveal explores every tile within minimapReveal of the player's tile.
+func (mm *Minimap) Reveal(md *MapData, tile [2]int) {
+	if tile == mm.last {
+		return
+	}
+	mm.last = tile
+
+	r := minimapReveal
+	for y := tile[1] - r; y <= tile[1]+r; y++ {
+		for x := tile[0] - r; x <= tile[0]+r; x++ {
+			if x < 0 || y < 0 || x >= mm.w || y >= mm.h {
+				continue
+			}
+			dx, dy := x-tile[0], y-tile[1]
+			if dx*dx+dy*dy > r*r {
+				continue
+			}
+			mm.explore(md, y*mm.w+x)
+		}
+	}
+}
+
+// explore uncovers tile i.
+func (mm *Minimap) explore(md *MapData, i int) {
+	if mm.explored[i] {
+		return
+	}
+	mm.explored[i] = true
+	col := minimapFloor
+	if md.IsSolidTile(i%mm.w, i/mm.w) {
+		col = minimapWall
+	}
+	copy(mm.pixels[i*4:], col[:])
+	mm.dirty = true
+}
+
+// ExploredBits packs the explored tiles, one bit per tile, for saving.
+func (mm *Minimap) ExploredBits() []byte {
+	bits := make([]byte, (len(mm.explored)+7)/8)
+	for i, e := range mm.explored {
+		if e {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+	return bits
+}
+
+// RestoreExplored uncovers every tile set in bits, as written by
+// ExploredBits for the same map.
+func (mm *Minimap) RestoreExplored(md *MapData, bits []byte) {
+	for i := range mm.explored {
+		if i/8 < len(bits) && bits[i/8]&(1<<(i%8)) != 0 {
+			mm.explore(md, i)
+		}
+	}
+}
+
+// Draw puts the minimap in the top-right corner of the screen with the
+// player and, once it is open, the portal on it.
+func (mm *Minimap) Draw(screen *ebiten.Image, md *MapData, player *Player) {
+	if mm.dirty {
+		mm.img.WritePixels(mm.pixels)
+		mm.dirty = false
+	}
+
+	scale := float64(minimapSize) / float64(max(mm.w, mm.h))
+	w, h := float64(mm.w)*scale, float64(mm.h)*scale
+	x := float64(screen.Bounds().Dx()) - w - minimapMargin
+	y := float64(minimapMargin)
+
+	vector.FillRect(screen, float32(x-4), float32(y-4), float32(w+8), float32(h+8), minimapBack, false)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(mm.img, op)
+
+	dot := func(t [2]int, col color.Color) {
+		s := float32(max(scale, 3))
+		cx := float32(x + (float64(t[0])+0.5)*scale)
+		cy := float32(y + (float64(t[1])+0.5)*scale)
+		vector.FillRect(screen, cx-s/2, cy-s/2, s, s, col, false)
+	}
+	if p := md.Portal; p != nil && p.Active {
+		px, py := md.TileAt(p.X+float64(p.Img.Bounds().Dx())/2, p.Y+float64(p.Img.Bounds().Dy())/2)
+		dot([2]int{px, py}, minimapPortal)
+	}
+	dot(player.Tile(md), minimapPlayer)
+}
@@ -0,0 +1,153 @@
+package game
+
+import "container/heap"
+
+// -------------------------------
+// A* over the tile collision grid
+// -------------------------------
+type pathNode struct {
+	tile  [2]int
+	cost  int // steps from the start
+	score int // cost + heuristic
+	index int // position in the heap
+}
+
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	return q[i].score < q[j].score
+}
+func (q pathQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *pathQueue) Push(x any) {
+	n := x.(*pathNode)
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+func manhattan(a, b [2]int) int {
+	dx := a[0] - b[0]
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := a[1] - b[1]
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// FindPath returns the tiles to walk through to get from one tile to
+// another (start excluded, goal included), moving in 4 directions over
+// non-solid tiles. It returns nil when the goal can't be reached.
+func (md *MapData) FindPath(from, to [2]int) [][2]int {
+	if from == to || md.IsSolidTile(to[0], to[1]) {
+		return nil
+	}
+
+	w := md.Map.Width
+	key := func(t [2]int) int { return t[1]*w + t[0] }
+
+	nodes := map[int]*pathNode{}
+	cameFrom := map[int][2]int{}
+	closed := map[int]bool{}
+
+	start := &pathNode{tile: from, score: manhattan(from, to)}
+	nodes[key(from)] = start
+	open := &pathQueue{}
+	heap.Push(open, start)
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(*pathNode)
+		if cur.tile == to {
+			var path [][2]int
+			for t := to; t != from; t = cameFrom[key(t)] {
+				path = append(path, t)
+			}
+			// reverse into walking order
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		closed[key(cur.tile)] = true
+
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			next := [2]int{cur.tile[0] + d[0], cur.tile[1] + d[1]}
+			if md.IsSolidTile(next[0], next[1]) || closed[key(next)] {
+				continue
+			}
+
+			cost := cur.cost + 1
+			n, seen := nodes[key(next)]
+			if seen && cost >= n.cost {
+				continue
+			}
+			cameFrom[key(next)] = cur.tile
+			if !seen {
+				n = &pathNode{tile: next}
+				nodes[key(next)] = n
+				n.cost = cost
+				n.score = cost + manhattan(next, to)
+				heap.Push(open, n)
+			} else {
+				n.cost = cost
+				n.score = cost + manhattan(next, to)
+				heap.Fix(open, n.index)
+			}
+		}
+	}
+	return nil
+}
+
+// LineOfSight walks the tiles between a and b (Bresenham) and reports
+// whether none of them are solid.
+func (md *MapData) LineOfSight(a, b [2]int) bool {
+	x0, y0 := a[0], a[1]
+	x1, y1 := b[0], b[1]
+
+	dx := x1 - x0
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := -(y1 - y0)
+	if dy > 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		if md.IsSolidTile(x0, y0) {
+			return false
+		}
+		if x0 == x1 && y0 == y1 {
+			return true
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Defaults used when a level does not set its own placement rules.
+const (
+	defaultMinSpacing = 2 // tiles between two pickups
+	defaultSafeRadius = 3 // tiles kept clear around the player spawn
+)
+
+// PlacementRules controls where randomly placed content may go.
+type PlacementRules struct {
+	MinSpacing int // minimum tile distance between pickups (items, bad items, portal)
+	SafeRadius int // nothing is placed within this many tiles of the spawn
+}
+
+// Placer hands out reachable, unoccupied tiles for random content.
+type Placer struct {
+	rng     *rand.Rand
+	rules   PlacementRules
+	spawn   [2]int
+	free    [][2]int // reachable tiles not taken yet
+	pickups [][2]int // tiles holding pickups, used for spacing
+}
+
+// -------------------------------
+// Collision grid helpers
+// -------------------------------
+// IsSolidTile reports whether the tile at (x, y) blocks movement.
+// Tiles outside the map count as solid.
+func (md *MapData) IsSolidTile(x, y int) bool {
+	if x < 0 || y < 0 || x >= md.Map.Width || y >= md.Map.Height {
+		return true
+	}
+	return md.solidGrid[y*md.Map.Width+x]
+}
+
+// IsPartialTile reports whether the tile has collision shapes that only
+// cover part of it. Such tiles can be walked through, but nothing is
+// placed on them.
+func (md *MapData) IsPartialTile(x, y int) bool {
+	if x < 0 || y < 0 || x >= md.Map.Width || y >= md.Map.Height {
+		return false
+	}
+	return md.partialGrid[y*md.Map.Width+x]
+}
+
+// TileAt converts a world position to tile coordinates.
+func (md *MapData) TileAt(x, y float64) (int, int) {
+	return int(x) / md.TileW, int(y) / md.TileH
+}
+
+// ReachableTiles flood-fills from (x, y) over non-solid tiles and returns
+// every tile that can be walked to, in breadth-first order.
+func (md *MapData) ReachableTiles(x, y int) [][2]int {
+	if md.IsSolidTile(x, y) {
+		return nil
+	}
+
+	w := md.Map.Width
+	seen := make([]bool, w*md.Map.Height)
+	seen[y*w+x] = true
+
+	queue := [][2]int{{x, y}}
+	for i := 0; i < len(queue); i++ {
+		cur := queue[i]
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := cur[0]+d[0], cur[1]+d[1]
+			if md.IsSolidTile(nx, ny) || seen[ny*w+nx] {
+				continue
+			}
+			seen[ny*w+nx] = true
+			queue = append(queue, [2]int{nx, ny})
+		}
+	}
+	return queue
+}
+
+// -------------------------------
+// Placer
+// -------------------------------
+
+// initPlacement prepares random placement for a level whose player
+// starts on tile (spawnX, spawnY).
+func (md *MapData) initPlacement(spawnX, spawnY int, rules PlacementRules) error {
+	p := &Placer{
+		rng:   md.rng,
+		rules: rules,
+		spawn: [2]int{spawnX, spawnY},
+	}
+	md.placer = p
+
+	reachable := md.ReachableTiles(spawnX, spawnY)
+	if len(reachable) == 0 {
+		return fmt.Errorf("player spawn tile (%d,%d) is inside a wall", spawnX, spawnY)
+	}
+
+	for _, t := range reachable {
+		if tileDist(t, p.spawn) > rules.SafeRadius && !md.IsPartialTile(t[0], t[1]) {
+			p.free = append(p.free, t)
+		}
+	}
+	md.EmptyTiles = p.free
+	return nil
+}
+
+// tileDist is the chessboard distance between two tiles.
+func tileDist(a, b [2]int) int {
+	dx := a[0] - b[0]
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := a[1] - b[1]
+	if dy < 0 {
+		dy = -dy
+	}
+	return max(dx, dy)
+}
+
+// Place picks up to n free tiles at random. Pickups also respect
+// MinSpacing against every pickup placed before them.
+// An error is returned when fewer than n tiles could be found.
+func (p *Placer) Place(n int, pickup bool, what string) ([][2]int, error) {
+	var out [][2]int
+	if n <= 0 {
+		return out, nil
+	}
+
+	order := p.rng.Perm(len(p.free))
+	used := make([]bool, len(p.free))
+
+	for _, idx := range order {
+		if len(out) == n {
+			break
+		}
+		t := p.free[idx]
+		if pickup && !p.spacedFromPickups(t) {
+			continue
+		}
+		used[idx] = true
+		out = append(out, t)
+		if pickup {
+			p.pickups = append(p.pickups, t)
+		}
+	}
+
+	// Drop taken tiles so nothing is placed on top of anything else
+	remaining := make([][2]int, 0, len(p.free)-len(out))
+	for i, t := range p.free {
+		if !used[i] {
+			remaining = append(remaining, t)
+		}
+	}
+	p.free = remaining
+
+	if len(out) < n {
+		return out, fmt.Errorf("only %d of %d %s fit on reachable tiles", len(out), n, what)
+	}
+	return out, nil
+}
+
+// Reserve takes tile t out of the free tiles, for content that was put
+// there by hand. Pickups also count towards MinSpacing from then on.
+func (p *Placer) Reserve(t [2]int, pickup bool) {
+	for i, f := range p.free {
+		if f == t {
+			p.free = append(p.free[:i:i], p.free[i+1:]...)
+			break
+		}
+	}
+	if pickup {
+		p.pickups = append(p.pickups, t)
+	}
+}
+
+// reserveAt reserves the tile under a hand-placed object at (x, y).
+func (md *MapData) reserveAt(x, y float64, pickup bool) {
+	if md.placer == nil {
+		return
+	}
+	tx, ty := md.TileAt(x+float64(md.TileW)/2, y+float64(md.TileH)/2)
+	md.placer.Reserve([2]int{tx, ty}, pickup)
+	md.EmptyTiles = md.placer.free
+}
+
+// placeTiles places n things of kind what using the level's placer.
+func (md *MapData) placeTiles(n int, pickup bool, what string) ([][2]int, error) {
+	if md.placer == nil {
+		return nil, fmt.Errorf("no placer for %s: level placement was not initialised", what)
+	}
+	tiles, err := md.placer.Place(n, pickup, what)
+	md.EmptyTiles = md.placer.free
+	return tiles, err
+}
+
+func (p *Placer) spacedFromPickups(t [2]int) bool {
+	for _, other := range p.pickups {
+		if tileDist(t, other) < p.rules.MinSpacing {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/lafriks/go-tiled"
+)
+
+// gridMap builds a map from rows of '#' (solid), '+' (partial) and '.'
+// (floor) tiles.
+func gridMap(rows ...string) *MapData {
+	w, h := len(rows[0]), len(rows)
+	md := &MapData{
+		Map:         &tiled.Map{Width: w, Height: h, TileWidth: 32, TileHeight: 32},
+		TileW:       32,
+		TileH:       32,
+		Width:       w * 32,
+		Height:      h * 32,
+		solidGrid:   make([]bool, w*h),
+		partialGrid: make([]bool, w*h),
+		rng:         rand.New(rand.NewPCG(1, 2)),
+	}
+	for y, row := range rows {
+		for x, c := range row {
+			md.solidGrid[y*w+x] = c == '#'
+			md.partialGrid[y*w+x] = c == '+'
+		}
+	}
+	return md
+}
+
+func TestReachableTiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		start [2]int
+		want  int
+	}{
+		{"open room", []string{"...", "...", "..."}, [2]int{1, 1}, 9},
+		{"wall splits rooms", []string{"..#..", "..#..", "..#.."}, [2]int{0, 0}, 6},
+		{"gap in wall", []string{"..#..", ".....", "..#.."}, [2]int{0, 0}, 13},
+		{"partial tiles are walkable", []string{".+.", "#+#", "..."}, [2]int{0, 0}, 7},
+		{"start in wall", []string{"#..", "..."}, [2]int{0, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := gridMap(tt.rows...)
+			got := md.ReachableTiles(tt.start[0], tt.start[1])
+			if len(got) != tt.want {
+				t.Fatalf("got %d tiles, want %d", len(got), tt.want)
+			}
+			seen := map[[2]int]bool{}
+			for _, tile := range got {
+				if md.IsSolidTile(tile[0], tile[1]) {
+					t.Errorf("tile %v is solid", tile)
+				}
+				if seen[tile] {
+					t.Errorf("tile %v listed twice", tile)
+				}
+				seen[tile] = true
+			}
+		})
+	}
+}
+
+func TestPlacerPlace(t *testing.T) {
+	open7 := []string{".......", ".......", ".......", ".......", ".......", ".......", "......."}
+	tests := []struct {
+		name    string
+		rows    []string
+		spawn   [2]int
+		rules   PlacementRules
+		n       int
+		pickup  bool
+		want    int
+		wantErr bool
+	}{
+		{"spacing", open7, [2]int{3, 3}, PlacementRules{MinSpacing: 2, SafeRadius: 0}, 4, true, 4, false},
+		{"safe radius", open7, [2]int{3, 3}, PlacementRules{MinSpacing: 0, SafeRadius: 2}, 24, false, 24, false},
+		{"safe radius leaves too few", open7, [2]int{3, 3}, PlacementRules{MinSpacing: 0, SafeRadius: 2}, 25, false, 24, true},
+		{"spacing leaves too few", []string{"......."}, [2]int{0, 0}, PlacementRules{MinSpacing: 3, SafeRadius: 0}, 4, true, 2, true},
+		{"spacing ignored for non-pickups", []string{"......."}, [2]int{0, 0}, PlacementRules{MinSpacing: 3, SafeRadius: 0}, 6, false, 6, false},
+		{"unreachable and partial tiles skipped", []string{"..+#..", "...#.."}, [2]int{0, 0}, PlacementRules{}, 6, false, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := gridMap(tt.rows...)
+			if err := md.initPlacement(tt.spawn[0], tt.spawn[1], tt.rules); err != nil {
+				t.Fatal(err)
+			}
+			reachable := map[[2]int]bool{}
+			for _, tile := range md.ReachableTiles(tt.spawn[0], tt.spawn[1]) {
+				reachable[tile] = true
+			}
+
+			got, err := md.placeTiles(tt.n, tt.pickup, "things")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("placed %d, want %d", len(got), tt.want)
+			}
+			for i, a := range got {
+				if !reachable[a] || md.IsPartialTile(a[0], a[1]) {
+					t.Errorf("tile %v is not a free reachable tile", a)
+				}
+				if tileDist(a, tt.spawn) <= tt.rules.SafeRadius {
+					t.Errorf("tile %v is inside the safe radius", a)
+				}
+				for _, b := range got[:i] {
+					if a == b {
+						t.Errorf("tile %v placed twice", a)
+					}
+					if tt.pickup && tileDist(a, b) < tt.rules.MinSpacing {
+						t.Errorf("tiles %v and %v are closer than %d", a, b, tt.rules.MinSpacing)
+					}
+				}
+			}
+			for _, tile := range md.EmptyTiles {
+				for _, p := range got {
+					if tile == p {
+						t.Errorf("placed tile %v is still free", p)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,21 +1,55 @@
 package game
 
 import (
-	"bytes"
-	"image"
 	"log"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/solarlune/resolv"
 )
 
+const (
+	playerMaxHP       = 3
+	invulnTicks       = 60  // ~1s of flashing after a hit
+	knockbackSpeed    = 6.0 // px per tick right after a hit
+	knockbackFriction = 0.8
+
+	cornerNudge   = 6    // px the player is slid sideways around a corner
+	collisionSkin = 0.01 // gap left after pushing out of a wall
+	minWalkStep   = 0.1  // px per tick below which the player counts as standing
+)
+
+// dirNames name the animation clip for each Dir.
+var dirNames = [4]string{"down", "left", "right", "up"}
+
+// MoveStats are the movement tunables of one kind of player.
+type MoveStats struct {
+	Walk     float64 // top speed, px per tick
+	Run      float64 // top speed while Shift is held
+	Accel    float64 // px per tick added while a direction is held
+	Friction float64 // velocity kept per tick once the keys are let go
+}
+
+var playerMoveStats = MoveStats{Walk: 3, Run: 5, Accel: 0.6, Friction: 0.65}
+
 type Player struct {
-	Anim          [][]*ebiten.Image
+	Anim          *Animation
 	X, Y          float64
-	Dir, Frame    int
+	Dir           int // 0 down, 1 left, 2 right, 3 up
 	Box           resolv.IShape
 	HitboxOffsetX float64
 	HitboxOffsetY float64
+
+	Lantern bool // carries a lantern: different sheet, lights dark levels
+
+	// --- Movement ---
+	Stats  MoveStats
+	VX, VY float64 // walking velocity, px per tick
+
+	// --- Health ---
+	HP, MaxHP      int
+	Invuln         int // ticks left where hits are ignored
+	knockX, knockY float64
 }
 
 func NewPlayer(x, y float64) *Player {
@@ -24,8 +58,11 @@ func NewPlayer(x, y float64) *Player {
 		Y:             y,
 		HitboxOffsetX: 8,
 		HitboxOffsetY: 35,
+		HP:            playerMaxHP,
+		MaxHP:         playerMaxHP,
+		Stats:         playerMoveStats,
 	}
-	p.Anim = loadPlayerAnim()
+	p.Anim = mustLoadAnimation("Assets/Sprites/player.json")
 	p.Box = resolv.NewRectangle(
 		x+p.HitboxOffsetX, y+p.HitboxOffsetY,
 		16, 27,
@@ -33,89 +70,193 @@ func NewPlayer(x, y float64) *Player {
 	return p
 }
 
-func loadPlayerAnim() [][]*ebiten.Image {
-	data, _ := EmbeddedFS.ReadFile("Assets/Sprites/player.png")
-	img, _, err := image.Decode(bytes.NewReader(data))
+// mustLoadAnimation loads a sprite sheet that the game can't run without.
+func mustLoadAnimation(metaPath string) *Animation {
+	set, err := LoadAnimationSet(metaPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	sheet := ebiten.NewImageFromImage(img)
+	return NewAnimation(set)
+}
 
-	cols := 12
-	rows := 4
-	frameW := sheet.Bounds().Dx() / cols
-	frameH := sheet.Bounds().Dy() / rows
+// Update reads input and moves the player. md may be nil (the game-over
+// screen), in which case only the mapW x mapH bounds stop the player.
+func (p *Player) Update(input InputSource, md *MapData, mapW, mapH int) error {
+	var ix, iy float64
 
-	out := make([][]*ebiten.Image, rows)
-	for row := 0; row < rows; row++ {
-		for col := 0; col < cols; col++ {
-			sx := col * frameW
-			sy := row * frameH
-			sub := sheet.SubImage(image.Rect(sx, sy, sx+frameW, sy+frameH)).(*ebiten.Image)
-			out[row] = append(out[row], sub)
-		}
+	if input.IsKeyPressed(ebiten.KeyLeft) {
+		ix--
 	}
-	return out
-}
+	if input.IsKeyPressed(ebiten.KeyRight) {
+		ix++
+	}
+	if input.IsKeyPressed(ebiten.KeyUp) {
+		iy--
+	}
+	if input.IsKeyPressed(ebiten.KeyDown) {
+		iy++
+	}
+	moving := ix != 0 || iy != 0
 
-func (p *Player) Update(solids []resolv.IShape, mapW, mapH int) error {
-	speed := 3.0
-	moving := false
-	var dx, dy float64
+	// same top speed in every direction, diagonals included
+	top := p.Stats.Walk
+	if input.IsKeyPressed(ebiten.KeyShift) {
+		top = p.Stats.Run
+	}
+	if moving {
+		l := math.Hypot(ix, iy)
+		ix, iy = ix/l, iy/l
+	}
+	p.VX = p.Stats.accelerate(p.VX, ix*top)
+	p.VY = p.Stats.accelerate(p.VY, iy*top)
 
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
-		dx -= speed
-		p.Dir = 1
-		moving = true
+	if p.Invuln > 0 {
+		p.Invuln--
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) {
-		dx += speed
-		p.Dir = 2
-		moving = true
+
+	dx, dy := p.VX, p.VY
+
+	// knockback is added on top of walking and fades out quickly
+	if p.knockX != 0 || p.knockY != 0 {
+		dx += p.knockX
+		dy += p.knockY
+		p.knockX *= knockbackFriction
+		p.knockY *= knockbackFriction
+		if math.Hypot(p.knockX, p.knockY) < 0.3 {
+			p.knockX, p.knockY = 0, 0
+		}
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
-		dy -= speed
-		p.Dir = 3
-		moving = true
+
+	oldX, oldY := p.X, p.Y
+	if dx != 0 || dy != 0 {
+		p.move(dx, dy, md, mapW, mapH)
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
-		dy += speed
-		p.Dir = 0
-		moving = true
+
+	// face where the player actually went (wall slides, nudges and
+	// knockback included); pushing into a wall faces the wall
+	mx, my := p.X-oldX, p.Y-oldY
+	walked := math.Hypot(mx, my) > minWalkStep
+	if walked {
+		p.face(mx, my)
+	} else if moving {
+		p.face(ix, iy)
 	}
 
-	if moving {
-		p.move(dx, dy, solids, mapW, mapH)
-		p.Frame++
-	} else {
-		p.Frame = 0
+	// walk cycle speeds up with the player
+	clip := "idle_"
+	if walked {
+		clip = "walk_"
 	}
+	p.Anim.Play(clip + dirNames[p.Dir])
+	p.Anim.Update(tickMillis() * top / p.Stats.Walk)
 	return nil
 }
 
-func (p *Player) move(dx, dy float64, solids []resolv.IShape, mapW, mapH int) {
-	// --- Horizontal movement ---
-	if dx != 0 {
-		newX := p.X + dx
-		p.Box.SetPosition(newX+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
-		if p.collides(solids) {
-			// if we hit something horizontally, stop horizontal motion
-			p.Box.SetPosition(p.X+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
-		} else {
-			p.X = newX
+// accelerate moves v toward target while a key is held and lets
+// friction bring it back to rest otherwise.
+func (st MoveStats) accelerate(v, target float64) float64 {
+	if target == 0 {
+		v *= st.Friction
+		if math.Abs(v) < 0.05 {
+			v = 0
 		}
+		return v
 	}
+	if v < target {
+		return math.Min(v+st.Accel, target)
+	}
+	return math.Max(v-st.Accel, target)
+}
 
-	// --- Vertical movement ---
-	if dy != 0 {
-		newY := p.Y + dy
-		p.Box.SetPosition(p.X+p.HitboxOffsetX, newY+p.HitboxOffsetY)
-		if p.collides(solids) {
-			// if we hit something vertically, stop vertical motion
-			p.Box.SetPosition(p.X+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
-		} else {
-			p.Y = newY
-		}
+// face turns the player toward the axis of (dx, dy) they are mostly
+// moving along, so the animation row matches the motion. On an exact diagonal the
+// current facing is kept if it is one of the two directions.
+func (p *Player) face(dx, dy float64) {
+	horiz, vert := 2, 0 // right, down
+	if dx < 0 {
+		horiz = 1
+	}
+	if dy < 0 {
+		vert = 3
+	}
+
+	switch {
+	case math.Abs(dx) > math.Abs(dy):
+		p.Dir = horiz
+	case math.Abs(dy) > math.Abs(dx):
+		p.Dir = vert
+	case p.Dir != horiz && p.Dir != vert:
+		p.Dir = horiz
+	}
+}
+
+// -------------------------------
+// Health
+// -------------------------------
+
+// TakeDamage hurts the player and knocks them away from (fromX, fromY).
+// It returns false while the player is still invulnerable from the last hit.
+func (p *Player) TakeDamage(amount int, fromX, fromY float64) bool {
+	if p.Invuln > 0 || p.HP <= 0 {
+		return false
+	}
+	p.HP -= amount
+	if p.HP < 0 {
+		p.HP = 0
+	}
+	p.Invuln = invulnTicks
+
+	dx := p.X + p.HitboxOffsetX - fromX
+	dy := p.Y + p.HitboxOffsetY - fromY
+	if d := math.Hypot(dx, dy); d > 0 {
+		p.knockX = dx / d * knockbackSpeed
+		p.knockY = dy / d * knockbackSpeed
+	}
+	return true
+}
+
+// SetLantern gives the player the lantern (or takes it away), which
+// swaps the sprite sheet. Movement stays the same.
+func (p *Player) SetLantern(on bool) {
+	if p.Lantern == on {
+		return
+	}
+	p.Lantern = on
+	if on {
+		p.Anim = mustLoadAnimation("Assets/Sprites/player_lantern.json")
+	} else {
+		p.Anim = mustLoadAnimation("Assets/Sprites/player.json")
+	}
+}
+
+// Center is the middle of the player's sprite in world space.
+func (p *Player) Center() (float64, float64) {
+	b := p.Anim.Image().Bounds()
+	return p.X + float64(b.Dx())/2, p.Y + float64(b.Dy())/2
+}
+
+// Tile is the tile the player stands on. The hitbox sits half a tile
+// up-left of the art, like the wall boxes, hence the shift.
+func (p *Player) Tile(md *MapData) [2]int {
+	x, y := md.TileAt(p.X+p.HitboxOffsetX+float64(md.TileW/2), p.Y+p.HitboxOffsetY+float64(md.TileH/2))
+	return [2]int{x, y}
+}
+
+func (p *Player) Dead() bool {
+	return p.HP <= 0
+}
+
+// Visible is false on the "off" beats of the invulnerability flash.
+func (p *Player) Visible() bool {
+	return p.Invuln == 0 || (p.Invuln/4)%2 == 1
+}
+
+func (p *Player) move(dx, dy float64, md *MapData, mapW, mapH int) {
+	if dx != 0 && p.moveAxis(dx, 0, md) {
+		p.VX, p.knockX = 0, 0
+	}
+	if dy != 0 && p.moveAxis(0, dy, md) {
+		p.VY, p.knockY = 0, 0
 	}
 
 	// --- Clamp player to map boundaries ---
@@ -135,16 +276,75 @@ func (p *Player) move(dx, dy float64, solids []resolv.IShape, mapW, mapH int) {
 	}
 
 	// Update box position at end
-	p.Box.SetPosition(p.X+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
+	p.syncBox()
 }
 
-func (p *Player) collides(solids []resolv.IShape) bool {
-	for _, s := range solids {
-		if p.Box.IsIntersecting(s) {
+// moveAxis moves along one axis and resolves any wall it runs into. A
+// wall clipped by only a few pixels is slipped around (corner nudge);
+// otherwise the player is pushed back flush against it and moveAxis
+// reports true.
+func (p *Player) moveAxis(dx, dy float64, md *MapData) bool {
+	p.X += dx
+	p.Y += dy
+	p.syncBox()
+	if !p.collides(md) {
+		return false
+	}
+
+	for n := 1.0; n <= cornerNudge; n++ {
+		for _, side := range [2]float64{-n, n} {
+			// nudge on the axis we are not moving along
+			nx, ny := 0.0, side
+			if dx == 0 {
+				nx, ny = side, 0
+			}
+			p.Box.SetPosition(p.X+nx+p.HitboxOffsetX, p.Y+ny+p.HitboxOffsetY)
+			if !p.collides(md) {
+				p.X += nx
+				p.Y += ny
+				return false
+			}
+		}
+	}
+	p.syncBox()
+
+	// slide flush: push out along the axis we moved on
+	for i := 0; i < 4; i++ {
+		mtv, hit := md.SolidMTV(p.Box)
+		if !hit {
 			return true
 		}
+		push := mtv.X
+		if dx == 0 {
+			push = mtv.Y
+		}
+		if push == 0 || math.Abs(push) > math.Abs(dx+dy)+collisionSkin {
+			break // not something this step walked into
+		}
+		push += math.Copysign(collisionSkin, push)
+		if dx != 0 {
+			p.X += push
+		} else {
+			p.Y += push
+		}
+		p.syncBox()
 	}
-	return false
+
+	// still stuck: undo the step
+	if p.collides(md) {
+		p.X -= dx
+		p.Y -= dy
+		p.syncBox()
+	}
+	return true
+}
+
+func (p *Player) syncBox() {
+	p.Box.SetPosition(p.X+p.HitboxOffsetX, p.Y+p.HitboxOffsetY)
+}
+
+func (p *Player) collides(md *MapData) bool {
+	return md != nil && md.CollidesSolid(p.Box)
 }
 func NewLanternPlayer(x, y float64) *Player {
 	p := &Player{
@@ -152,35 +352,13 @@ func NewLanternPlayer(x, y float64) *Player {
 		Y:             y,
 		HitboxOffsetX: 8,
 		HitboxOffsetY: 35,
+		Stats:         playerMoveStats,
+		Lantern:       true,
 	}
-	p.Anim = loadLanternAnim()
+	p.Anim = mustLoadAnimation("Assets/Sprites/player_lantern.json")
 	p.Box = resolv.NewRectangle(
 		x+p.HitboxOffsetX, y+p.HitboxOffsetY,
 		16, 27,
 	)
 	return p
 }
-func loadLanternAnim() [][]*ebiten.Image {
-	data, _ := EmbeddedFS.ReadFile("Assets/Sprites/player_lantern.png")
-	img, _, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		log.Fatal(err)
-	}
-	sheet := ebiten.NewImageFromImage(img)
-
-	cols := 12
-	rows := 4
-	frameW := sheet.Bounds().Dx() / cols
-	frameH := sheet.Bounds().Dy() / rows
-
-	out := make([][]*ebiten.Image, rows)
-	for row := 0; row < rows; row++ {
-		for col := 0; col < cols; col++ {
-			sx := col * frameW
-			sy := row * frameH
-			sub := sheet.SubImage(image.Rect(sx, sy, sx+frameW, sy+frameH)).(*ebiten.Image)
-			out[row] = append(out[row], sub)
-		}
-	}
-	return out
-}
@@ -0,0 +1,194 @@
+package game
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Replay file layout (all numbers are uvarints):
+//
+//	"PPRP" version seed runCount { ticks mask }...
+//
+// Each run is a number of ticks with the same tracked keys held, so
+// long walks cost a couple of bytes.
+const (
+	replayMagic   = "PPRP"
+	replayVersion = 1
+)
+
+type replayRun struct {
+	Ticks uint64
+	Mask  uint64
+}
+
+// Replay is a recorded input stream plus the seed it was played with.
+type Replay struct {
+	Seed uint64
+	Runs []replayRun
+}
+
+func (r *Replay) add(mask uint64) {
+	if n := len(r.Runs); n > 0 && r.Runs[n-1].Mask == mask {
+		r.Runs[n-1].Ticks++
+		return
+	}
+	r.Runs = append(r.Runs, replayRun{Ticks: 1, Mask: mask})
+}
+
+// Ticks is the length of the replay.
+func (r *Replay) Ticks() int {
+	total := 0
+	for _, run := range r.Runs {
+		total += int(run.Ticks)
+	}
+	return total
+}
+
+// -------------------------------
+// Encoding
+// -------------------------------
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	buf := []byte(replayMagic)
+	buf = binary.AppendUvarint(buf, replayVersion)
+	buf = binary.AppendUvarint(buf, r.Seed)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Runs)))
+	for _, run := range r.Runs {
+		buf = binary.AppendUvarint(buf, run.Ticks)
+		buf = binary.AppendUvarint(buf, run.Mask)
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+func ReadReplay(rd io.Reader) (*Replay, error) {
+	br := bufio.NewReader(rd)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if version != replayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	r := &Replay{}
+	if r.Seed, err = binary.ReadUvarint(br); err != nil {
+		return nil, err
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		var run replayRun
+		if run.Ticks, err = binary.ReadUvarint(br); err != nil {
+			return nil, fmt.Errorf("run %d: %w", i, err)
+		}
+		if run.Mask, err = binary.ReadUvarint(br); err != nil {
+			return nil, fmt.Errorf("run %d: %w", i, err)
+		}
+		r.Runs = append(r.Runs, run)
+	}
+	return r, nil
+}
+
+func LoadReplayFile(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+func (r *Replay) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// -------------------------------
+// Recording
+// -------------------------------
+
+// RecordingInput passes another source through and remembers the tracked
+// keys for every tick. Only tracked keys are reported as pressed, so what
+// the game sees is exactly what gets saved.
+type RecordingInput struct {
+	src    InputSource
+	replay *Replay
+	mask   uint64
+}
+
+func NewRecordingInput(src InputSource, seed uint64) *RecordingInput {
+	return &RecordingInput{src: src, replay: &Replay{Seed: seed}}
+}
+
+func (ri *RecordingInput) Update() {
+	ri.src.Update()
+	ri.mask = keyMask(ri.src)
+	ri.replay.add(ri.mask)
+}
+
+func (ri *RecordingInput) IsKeyPressed(key ebiten.Key) bool {
+	return maskHasKey(ri.mask, key)
+}
+
+func (ri *RecordingInput) Replay() *Replay {
+	return ri.replay
+}
+
+// -------------------------------
+// Playback
+// -------------------------------
+
+// ReplayInput feeds a recorded stream back into the game, one run at a time.
+type ReplayInput struct {
+	replay *Replay
+	run    int
+	left   uint64 // ticks left in the current run
+	mask   uint64
+}
+
+func NewReplayInput(r *Replay) *ReplayInput {
+	return &ReplayInput{replay: r, run: -1}
+}
+
+func (pi *ReplayInput) Update() {
+	for pi.left == 0 {
+		if pi.run+1 >= len(pi.replay.Runs) {
+			pi.run = len(pi.replay.Runs)
+			pi.mask = 0
+			return
+		}
+		pi.run++
+		pi.left = pi.replay.Runs[pi.run].Ticks
+		pi.mask = pi.replay.Runs[pi.run].Mask
+	}
+	pi.left--
+}
+
+func (pi *ReplayInput) IsKeyPressed(key ebiten.Key) bool {
+	return maskHasKey(pi.mask, key)
+}
+
+// Done reports whether the whole replay has been played.
+func (pi *ReplayInput) Done() bool {
+	return pi.run >= len(pi.replay.Runs)
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		replay Replay
+	}{
+		{"empty", Replay{Seed: 7}},
+		{"one run", Replay{Seed: 42, Runs: []replayRun{{Ticks: 60, Mask: 0b10}}}},
+		{"big numbers", Replay{Seed: 1<<64 - 1, Runs: []replayRun{
+			{Ticks: 1, Mask: 0},
+			{Ticks: 1 << 40, Mask: 1<<15 - 1},
+			{Ticks: 300, Mask: 0b1001},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.replay.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+			}
+			got, err := ReadReplay(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.replay) {
+				t.Errorf("got %+v, want %+v", *got, tt.replay)
+			}
+		})
+	}
+}
+
+func TestReadReplayRejectsBadFiles(t *testing.T) {
+	var good bytes.Buffer
+	(&Replay{Seed: 3, Runs: []replayRun{{Ticks: 5, Mask: 1}}}).WriteTo(&good)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"wrong magic", append([]byte("XXXX"), good.Bytes()[4:]...)},
+		{"wrong version", append([]byte(replayMagic), 99, 3, 0)},
+		{"truncated", good.Bytes()[:good.Len()-1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadReplay(bytes.NewReader(tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestReplayInputRunBoundaries records a script and checks playback
+// switches keys on exactly the ticks the recording did.
+func TestReplayInputRunBoundaries(t *testing.T) {
+	script := []ScriptStep{
+		Hold(2, ebiten.KeyRight),
+		Hold(1, ebiten.KeyRight, ebiten.KeyUp),
+		Wait(2),
+		Hold(1, ebiten.KeyF5),
+	}
+	ticks := 0
+	for _, s := range script {
+		ticks += s.Ticks
+	}
+
+	rec := NewRecordingInput(NewScriptedInput(script...), 1)
+	for i := 0; i < ticks; i++ {
+		rec.Update()
+	}
+	r := rec.Replay()
+	if len(r.Runs) != len(script) || r.Ticks() != ticks {
+		t.Fatalf("recorded %d runs over %d ticks, want %d over %d", len(r.Runs), r.Ticks(), len(script), ticks)
+	}
+
+	want := NewScriptedInput(script...)
+	play := NewReplayInput(r)
+	for i := 0; i < ticks; i++ {
+		want.Update()
+		play.Update()
+		for _, k := range trackedKeys {
+			if play.IsKeyPressed(k) != want.IsKeyPressed(k) {
+				t.Errorf("tick %d: key %v pressed = %v, want %v", i, k, play.IsKeyPressed(k), want.IsKeyPressed(k))
+			}
+		}
+		if play.Done() {
+			t.Fatalf("tick %d: done before the last run was played", i)
+		}
+	}
+
+	play.Update()
+	if !play.Done() {
+		t.Error("not done after the last run")
+	}
+	for _, k := range trackedKeys {
+		if play.IsKeyPressed(k) {
+			t.Errorf("key %v still pressed after the replay ended", k)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// saveVersion is bumped whenever SaveData changes shape.
+const saveVersion = 4
+
+// -------------------------------
+// Save file structures
+// -------------------------------
+type SavedPos struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type SavedPlayer struct {
+	X   float64 `json:"x"`
+	Y   float64 `json:"y"`
+	Dir int     `json:"dir"`
+	HP  int     `json:"hp"`
+}
+
+type SavedPortal struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Active bool    `json:"active"`
+}
+
+type SavedEnemy struct {
+	X         float64  `json:"x"`
+	Y         float64  `json:"y"`
+	Behaviour string   `json:"behaviour"`
+	Waypoints [][2]int `json:"waypoints,omitempty"`
+}
+
+type SaveData struct {
+	Version   int          `json:"version"`
+	Seed      uint64       `json:"seed"`
+	Level     int          `json:"level"`
+	Player    SavedPlayer  `json:"player"`
+	Collected int          `json:"collected"`
+	Items     []SavedPos   `json:"items"`
+	BadItems  []SavedPos   `json:"bad_items"`
+	Portal    *SavedPortal `json:"portal,omitempty"`
+	Enemies   []SavedEnemy `json:"enemies"`
+	Explored  []byte       `json:"explored"` // minimap fog of war, one bit per tile
+}
+
+// SavePath is where the quick-save lives, inside the user's config dir.
+func SavePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "programProject2", "save.json"), nil
+}
+
+// HasSave reports whether there is a save to continue from.
+func HasSave() bool {
+	path, err := SavePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// -------------------------------
+// Save
+// -------------------------------
+func (g *Game) Save() error {
+	md := g.MapData
+	data := SaveData{
+		Version: saveVersion,
+		Seed:    g.Seed,
+		Level:   g.level,
+		Player: SavedPlayer{
+			X:   g.Player.X,
+			Y:   g.Player.Y,
+			Dir: g.Player.Dir,
+			HP:  g.Player.HP,
+		},
+		Collected: md.Collected,
+		Explored:  g.minimap.ExploredBits(),
+	}
+	for _, it := range md.Items {
+		data.Items = append(data.Items, SavedPos{X: it.X, Y: it.Y})
+	}
+	for _, it := range md.BadItems {
+		data.BadItems = append(data.BadItems, SavedPos{X: it.X, Y: it.Y})
+	}
+	if md.Portal != nil {
+		data.Portal = &SavedPortal{X: md.Portal.X, Y: md.Portal.Y, Active: md.Portal.Active}
+	}
+	for _, e := range md.Enemies {
+		data.Enemies = append(data.Enemies, SavedEnemy{X: e.X, Y: e.Y, Behaviour: e.Behaviour, Waypoints: e.Waypoints})
+	}
+
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so a crash never leaves half a save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// -------------------------------
+// Load
+// -------------------------------
+func (g *Game) Load() error {
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var data SaveData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("corrupt save: %w", err)
+	}
+	if data.Version != saveVersion {
+		return fmt.Errorf("save version %d is not supported (want %d)", data.Version, saveVersion)
+	}
+	if g.levels.Level(data.Level) == nil {
+		return fmt.Errorf("save refers to unknown level %d", data.Level)
+	}
+
+	// Rebuild the level from the same seed, then put everything back
+	// where it was when the game was saved.
+	g.Seed = data.Seed
+	g.State = StatePlaying
+	g.GameOverPlayer = nil
+	g.Heart = nil
+	g.floatTexts = nil
+	g.portalTextTimer = 0
+	g.portalAlpha = 0
+
+	g.Player = NewPlayer(0, 0)
+	g.LoadLevel(data.Level)
+
+	g.Player.X = data.Player.X
+	g.Player.Y = data.Player.Y
+	g.Player.Dir = data.Player.Dir
+	if data.Player.HP > 0 {
+		g.Player.HP = min(data.Player.HP, g.Player.MaxHP)
+	}
+	g.Player.Box.SetPosition(g.Player.X+g.Player.HitboxOffsetX, g.Player.Y+g.Player.HitboxOffsetY)
+	g.centerCamera()
+	g.minimap.RestoreExplored(g.MapData, data.Explored)
+	g.minimap.Reveal(g.MapData, g.Player.Tile(g.MapData))
+
+	md := g.MapData
+	md.Collected = data.Collected
+
+	if !md.loadItemImages() {
+		return errors.New("item sprites missing")
+	}
+	md.clearItems()
+	for _, p := range data.Items {
+		md.addItem(p.X, p.Y)
+	}
+	for _, p := range data.BadItems {
+		md.addBadItem(p.X, p.Y)
+	}
+
+	md.setPortal(nil)
+	if data.Portal != nil {
+		md.setPortal(md.newPortal(data.Portal.X, data.Portal.Y, data.Portal.Active))
+	}
+
+	md.Enemies = nil
+	if len(data.Enemies) > 0 {
+		sprites := LoadEnemySprites()
+		for _, p := range data.Enemies {
+			enemy := newEnemy(p.X, p.Y, sprites, p.Behaviour)
+			enemy.Waypoints = p.Waypoints
+			md.Enemies = append(md.Enemies, enemy)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// -------------------------------
+// Menus for the non-playing states
+// -------------------------------
+func (g *Game) initMenus() {
+	g.titleMenu = &Menu{
+		Title: gameTitle,
+		Items: []MenuItem{
+			{Label: "New Game", Action: g.RestartGame},
+			{Label: "Continue", Enabled: g.canLoad, Action: g.continueGame},
+			{Label: "Options", Action: func() { g.openOptions(StateTitle) }},
+			{Label: "Quit", Action: func() { g.quit = true }},
+		},
+	}
+
+	g.pauseMenu = &Menu{
+		Title: "Paused",
+		Items: []MenuItem{
+			{Label: "Resume", Action: func() { g.State = StatePlaying }},
+			{Label: "Options", Action: func() { g.openOptions(StatePaused) }},
+			{Label: "Quit to Title", Action: g.quitToTitle},
+		},
+	}
+
+	g.optionsMenu = &Menu{
+		Title: "Options",
+		Items: []MenuItem{
+			{
+				Label:  "Fullscreen",
+				Value:  func() string { return onOff(ebiten.IsFullscreen()) },
+				Action: func() { ebiten.SetFullscreen(!ebiten.IsFullscreen()) },
+			},
+			{
+				Label:  "Pixel Perfect",
+				Value:  func() string { return onOff(g.pixelPerfect) },
+				Action: func() { g.setPixelPerfect(!g.pixelPerfect) },
+			},
+			volumeItem("Master Volume", &g.audio.Master),
+			volumeItem("Music Volume", &g.audio.Music),
+			volumeItem("SFX Volume", &g.audio.SFX),
+			{Label: "Back", Action: g.closeOptions},
+		},
+	}
+
+	g.completeMenu = &Menu{
+		Title: "Level Complete!",
+		Items: []MenuItem{
+			{Label: "Next Level", Action: g.nextLevel},
+			{Label: "Quit to Title", Action: g.quitToTitle},
+		},
+	}
+}
+
+// volumeItem is a 0-100% slider; Enter steps it up and wraps to 0.
+func volumeItem(label string, v *float64) MenuItem {
+	return MenuItem{
+		Label: label,
+		Value: func() string { return fmt.Sprintf("%.0f%%", *v*100) },
+		Action: func() {
+			if *v >= 1 {
+				*v = 0
+			} else {
+				*v = stepVolume(*v, 1)
+			}
+		},
+		Adjust: func(step int) { *v = stepVolume(*v, step) },
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+// -------------------------------
+// Transitions
+// -------------------------------
+func (g *Game) continueGame() {
+	if err := g.Load(); err != nil {
+		log.Printf("⚠️ Could not continue saved game: %v", err)
+		g.showNotice("Load failed")
+	}
+}
+
+func (g *Game) openOptions(from GameState) {
+	g.optionsReturn = from
+	g.optionsMenu.Selected = 0
+	g.State = StateOptions
+}
+
+func (g *Game) closeOptions() {
+	g.State = g.optionsReturn
+}
+
+func (g *Game) quitToTitle() {
+	g.titleMenu.Selected = 0
+	g.State = StateTitle
+}
+
+func (g *Game) nextLevel() {
+	g.LoadLevel(g.levelDef.Next)
+	g.State = StatePlaying
+}
+
+// -------------------------------
+// Per-state updates
+// -------------------------------
+
+// updatePaused keeps the level frozen; only the menu runs.
+func (g *Game) updatePaused() {
+	if g.justPressed(ebiten.KeyEscape) {
+		g.State = StatePlaying
+		return
+	}
+	g.pauseMenu.Update(g)
+}
+
+func (g *Game) updateOptions() {
+	if g.justPressed(ebiten.KeyEscape) {
+		g.closeOptions()
+		return
+	}
+	g.optionsMenu.Update(g)
+}
+
+// drawDim darkens whatever is behind an overlay menu.
+func drawDim(screen *ebiten.Image) {
+	b := screen.Bounds()
+	vector.FillRect(screen, 0, 0, float32(b.Dx()), float32(b.Dy()), color.RGBA{0, 0, 0, 170}, false)
+}
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"log"
+	"math"
+
+	"github.com/lafriks/go-tiled"
+	"github.com/solarlune/resolv"
+)
+
+// -------------------------------
+// Per-tile collision shapes
+// -------------------------------
+
+// tileCollision is what a tileset tile contributes to collision.
+type tileCollision struct {
+	Full   bool       // blocks the whole cell
+	Shapes []tileBody // partial shapes, relative to the tile's top-left
+}
+
+// tileBody is one convex piece of an object from Tiled's tile collision
+// editor.
+type tileBody struct {
+	X, Y, W, H float64
+	Points     []float64 // convex polygon vertices relative to (X, Y); nil for a box
+}
+
+// ellipseSegments is how many sides the polygon standing in for a Tiled
+// ellipse has.
+const ellipseSegments = 16
+
+// collisionFor reads a tile's collision. Shapes drawn in Tiled's
+// collision editor win; the `solid` property is only a fallback for
+// tiles that have none. Object rotation and tile flips are not applied.
+func collisionFor(ts *tiled.TilesetTile, tileW, tileH int) tileCollision {
+	if ts == nil {
+		return tileCollision{}
+	}
+
+	var out tileCollision
+	for _, group := range ts.ObjectGroups {
+		for _, o := range group.Objects {
+			b := tileBody{
+				X: o.X + float64(group.OffsetX),
+				Y: o.Y + float64(group.OffsetY),
+				W: o.Width,
+				H: o.Height,
+			}
+			switch {
+			case len(o.Polygons) > 0:
+				if o.Polygons[0].Points == nil {
+					continue
+				}
+				var pts []float64
+				for _, p := range *o.Polygons[0].Points {
+					pts = append(pts, p.X, p.Y)
+				}
+				if len(pts) < 6 {
+					continue
+				}
+				// resolv only handles convex polygons
+				pieces := convexPieces(pts)
+				if pieces == nil {
+					log.Printf("⚠️ Tile %d: collision polygon crosses itself, ignored", ts.ID)
+				}
+				for _, piece := range pieces {
+					b.Points = piece
+					out.Shapes = append(out.Shapes, b)
+				}
+				continue
+			case len(o.Ellipses) > 0:
+				if o.Width <= 0 || o.Height <= 0 {
+					continue
+				}
+				b.Points = ellipsePoints(o.Width, o.Height)
+			case len(o.PolyLines) > 0 || o.Width <= 0 || o.Height <= 0:
+				continue // lines and points don't block anything
+			}
+			out.Shapes = append(out.Shapes, b)
+		}
+	}
+
+	if len(out.Shapes) == 0 {
+		out.Full = ts.Properties != nil && ts.Properties.GetBool("solid")
+		return out
+	}
+
+	// a single box over the whole tile is just a solid tile, and can be
+	// merged with its neighbours
+	if len(out.Shapes) == 1 {
+		b := out.Shapes[0]
+		if b.Points == nil && b.X <= 0 && b.Y <= 0 &&
+			b.X+b.W >= float64(tileW) && b.Y+b.H >= float64(tileH) {
+			return tileCollision{Full: true}
+		}
+	}
+	return out
+}
+
+// shape places the body in the world for a tile whose collision-space
+// top-left is (x, y).
+func (b tileBody) shape(x, y float64) resolv.IShape {
+	if b.Points != nil {
+		return resolv.NewConvexPolygon(x+b.X, y+b.Y, b.Points)
+	}
+	return resolv.NewRectangleFromTopLeft(x+b.X, y+b.Y, b.W, b.H)
+}
+
+// ellipsePoints approximates a w x h ellipse, top-left at the origin
+// like Tiled's, with a convex polygon.
+func ellipsePoints(w, h float64) []float64 {
+	pts := make([]float64, 0, ellipseSegments*2)
+	for i := 0; i < ellipseSegments; i++ {
+		a := 2 * math.Pi * float64(i) / ellipseSegments
+		pts = append(pts, w/2+w/2*math.Cos(a), h/2+h/2*math.Sin(a))
+	}
+	return pts
+}
+
+// convexPieces returns a convex polygon (flat x, y pairs) as it is and
+// cuts a concave one into triangles by ear clipping. It returns nil for
+// polygons that cross themselves.
+func convexPieces(pts []float64) [][]float64 {
+	n := len(pts) / 2
+	vx := func(i int) (float64, float64) { return pts[2*i], pts[2*i+1] }
+	cross := func(a, b, c int) float64 {
+		ax, ay := vx(a)
+		bx, by := vx(b)
+		cx, cy := vx(c)
+		return (bx-ax)*(cy-by) - (by-ay)*(cx-bx)
+	}
+
+	// winding: > 0 when the vertices turn the same way as the polygon
+	area := 0.0
+	for i := 0; i < n; i++ {
+		area += cross(0, i, (i+1)%n)
+	}
+	if area == 0 {
+		return nil
+	}
+	convex := true
+	for i := 0; i < n; i++ {
+		if cross(i, (i+1)%n, (i+2)%n)*area < 0 {
+			convex = false
+			break
+		}
+	}
+	if convex {
+		return [][]float64{pts}
+	}
+
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	inside := func(p, a, b, c int) bool {
+		return cross(a, b, p)*area >= 0 && cross(b, c, p)*area >= 0 && cross(c, a, p)*area >= 0
+	}
+	triangle := func(a, b, c int) []float64 {
+		ax, ay := vx(a)
+		bx, by := vx(b)
+		cx, cy := vx(c)
+		return []float64{ax, ay, bx, by, cx, cy}
+	}
+
+	var out [][]float64
+	for len(idx) > 3 {
+		clipped := false
+		for i := range idx {
+			a, b, c := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+			turn := cross(a, b, c) * area
+			if turn < 0 {
+				continue // reflex corner
+			}
+			if turn > 0 {
+				ear := true
+				for _, p := range idx {
+					if p != a && p != b && p != c && inside(p, a, b, c) {
+						ear = false
+						break
+					}
+				}
+				if !ear {
+					continue
+				}
+				out = append(out, triangle(a, b, c))
+			}
+			// a straight corner adds no area and is just dropped
+			idx = append(idx[:i], idx[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil
+		}
+	}
+	if cross(idx[0], idx[1], idx[2]) != 0 {
+		out = append(out, triangle(idx[0], idx[1], idx[2]))
+	}
+	return out
+}
+
+// tilesetTile looks a tile up by its local id; tilesets only list the
+// tiles that have properties, shapes or animations.
+func tilesetTile(tile *tiled.LayerTile) *tiled.TilesetTile {
+	if tile == nil || tile.Tileset == nil {
+		return nil
+	}
+	ts, err := tile.Tileset.GetTilesetTile(tile.ID)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
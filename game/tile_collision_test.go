@@ -0,0 +1,53 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// polyArea is the unsigned area of a polygon given as flat x, y pairs.
+func polyArea(pts []float64) float64 {
+	a := 0.0
+	n := len(pts) / 2
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		a += pts[2*i]*pts[2*j+1] - pts[2*j]*pts[2*i+1]
+	}
+	return math.Abs(a) / 2
+}
+
+func TestConvexPieces(t *testing.T) {
+	tests := []struct {
+		name   string
+		pts    []float64
+		pieces int // 0 means rejected
+	}{
+		{"square", []float64{0, 0, 32, 0, 32, 32, 0, 32}, 1},
+		{"square with a straight corner", []float64{0, 0, 16, 0, 32, 0, 32, 32, 0, 32}, 1},
+		{"L shape", []float64{0, 0, 32, 0, 32, 16, 16, 16, 16, 32, 0, 32}, 4},
+		{"L shape, other winding", []float64{0, 0, 0, 32, 16, 32, 16, 16, 32, 16, 32, 0}, 4},
+		{"notch", []float64{0, 0, 32, 0, 16, 8, 32, 32, 0, 32}, 3},
+		{"bowtie", []float64{0, 0, 32, 32, 32, 0, 0, 32}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pieces := convexPieces(tt.pts)
+			if len(pieces) != tt.pieces {
+				t.Fatalf("got %d pieces, want %d", len(pieces), tt.pieces)
+			}
+			if tt.pieces == 0 {
+				return
+			}
+			total := 0.0
+			for _, p := range pieces {
+				if got := convexPieces(p); len(got) != 1 {
+					t.Errorf("piece %v is not convex", p)
+				}
+				total += polyArea(p)
+			}
+			if math.Abs(total-polyArea(tt.pts)) > 1e-9 {
+				t.Errorf("pieces cover %v, polygon is %v", total, polyArea(tt.pts))
+			}
+		})
+	}
+}